@@ -0,0 +1,102 @@
+// Command cloudlab-reaper terminates stray experiments, such as the ones
+// failed CI runs leave behind, using the provider's portal client.
+//
+// It only lists what it would terminate unless -terminate is given, and
+// terminating requires -older-than so runs still in progress are left alone.
+//
+//	cloudlab-reaper -project my-proj -match 'tf-ci-*' -older-than 6h
+//	cloudlab-reaper -project my-proj -match 'tf-ci-*' -older-than 6h -terminate
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+	_ "time/tzdata" // -portal-zone must resolve on hosts without zoneinfo
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+type patterns []string
+
+func (p *patterns) String() string     { return strings.Join(*p, ",") }
+func (p *patterns) Set(v string) error { *p = append(*p, v); return nil }
+
+func main() {
+	var (
+		s         portalclient.Settings
+		match     patterns
+		terminate bool
+		zone      string
+		jsonOut   bool
+		olderThan time.Duration
+		expiresIn time.Duration
+	)
+	flag.StringVar(&s.Project, "project", os.Getenv("CLOUDLAB_PROJECT"), "project to reap (default $CLOUDLAB_PROJECT)")
+	flag.StringVar(&s.PemPath, "pem", portalclient.DefaultPemPath, "decrypted CloudLab PEM (certificate and key)")
+	flag.StringVar(&s.Server, "server", portalclient.DefaultServer, "portal XML-RPC server")
+	flag.IntVar(&s.Port, "port", portalclient.DefaultPort, "portal XML-RPC port")
+	flag.StringVar(&s.Path, "path", portalclient.DefaultPath, "portal XML-RPC path")
+	flag.StringVar(&s.Timeout, "timeout", portalclient.DefaultTimeout, "per-call timeout")
+	flag.Var(&match, "match", "experiment name glob, e.g. 'tf-ci-*' (repeatable, required)")
+	flag.DurationVar(&olderThan, "older-than", 0, "only experiments created at least this long ago")
+	flag.DurationVar(&expiresIn, "expires-within", 0, "only experiments expiring within this long")
+	flag.BoolVar(&terminate, "terminate", false, "terminate matches (default: only list them)")
+	flag.StringVar(&zone, "portal-zone", "America/Denver", "time zone of portal timestamps that carry none")
+	flag.BoolVar(&jsonOut, "json", false, "print results as JSON")
+	flag.Parse()
+
+	if s.Project == "" || len(match) == 0 {
+		fmt.Fprintln(os.Stderr, "cloudlab-reaper: -project and at least one -match are required")
+		flag.Usage()
+		os.Exit(2)
+	}
+	if terminate && olderThan <= 0 {
+		fmt.Fprintln(os.Stderr, "cloudlab-reaper: -terminate requires -older-than, so experiments still in use are not reaped")
+		os.Exit(2)
+	}
+	loc, err := time.LoadLocation(zone)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cloudlab-reaper: bad -portal-zone:", err)
+		os.Exit(2)
+	}
+	sel := newRules(match, olderThan, expiresIn, time.Now(), loc)
+	if err := sel.check(); err != nil {
+		fmt.Fprintln(os.Stderr, "cloudlab-reaper:", err)
+		os.Exit(2)
+	}
+
+	cfg, err := portalclient.Configure(s)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cloudlab-reaper:", err)
+		os.Exit(1)
+	}
+	all, err := portalclient.ListExperiments(cfg.Client, s.Project)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cloudlab-reaper: listing experiments:", err)
+		os.Exit(1)
+	}
+
+	results := reap(cfg, s.Project, all, sel, !terminate)
+	if jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(results)
+	} else {
+		for _, r := range results {
+			line := fmt.Sprintf("%-16s %s (created %s, expires %s)", r.Action, r.Name, r.Created, r.Expires)
+			if r.Error != "" {
+				line += ": " + r.Error
+			}
+			fmt.Println(line)
+		}
+	}
+	for _, r := range results {
+		if r.Action == actionFailed {
+			os.Exit(1)
+		}
+	}
+}
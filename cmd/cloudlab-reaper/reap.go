@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+const (
+	actionTerminated = "terminated"
+	actionWould      = "would-terminate"
+	actionFailed     = "failed"
+)
+
+type result struct {
+	Name    string `json:"name"`
+	UUID    string `json:"uuid"`
+	Status  string `json:"status"`
+	Creator string `json:"creator"`
+	Created string `json:"created"`
+	Expires string `json:"expires"`
+	Action  string `json:"action"`
+	Error   string `json:"error,omitempty"`
+}
+
+// rules select experiments by name glob and, optionally, age and expiry.
+type rules struct {
+	patterns  []string
+	olderThan time.Duration
+	expiresIn time.Duration
+	now       time.Time
+	loc       *time.Location // zone of timestamps that carry none
+}
+
+func newRules(patterns []string, olderThan, expiresIn time.Duration, now time.Time, loc *time.Location) rules {
+	return rules{patterns: patterns, olderThan: olderThan, expiresIn: expiresIn, now: now, loc: loc}
+}
+
+func (r rules) check() error {
+	for _, p := range r.patterns {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("bad -match pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// The portal has used both RFC3339 and plain "date time" forms; the latter
+// are in the portal's local zone.
+var timeLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05"}
+
+func (r rules) parseTime(v string) (time.Time, bool) {
+	for _, l := range timeLayouts {
+		if t, err := time.ParseInLocation(l, v, r.loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// matches reports whether e should be reaped. An experiment whose timestamp
+// cannot be parsed never passes an age or expiry rule; skip then explains
+// why a name match was left alone.
+func (r rules) matches(e portalclient.ExperimentInfo) (ok bool, skip string) {
+	named := false
+	for _, p := range r.patterns {
+		if m, _ := path.Match(p, e.Name); m {
+			named = true
+			break
+		}
+	}
+	if !named {
+		return false, ""
+	}
+	if r.olderThan > 0 {
+		created, ok := r.parseTime(e.Created)
+		if !ok {
+			return false, fmt.Sprintf("unparsable created time %q", e.Created)
+		}
+		if r.now.Sub(created) < r.olderThan {
+			return false, ""
+		}
+	}
+	if r.expiresIn > 0 {
+		expires, ok := r.parseTime(e.Expires)
+		if !ok {
+			return false, fmt.Sprintf("unparsable expiry time %q", e.Expires)
+		}
+		if expires.Sub(r.now) > r.expiresIn {
+			return false, ""
+		}
+	}
+	return true, ""
+}
+
+func reap(cfg *portalclient.Config, project string, all []portalclient.ExperimentInfo, r rules, dryRun bool) []result {
+	out := []result{}
+	for _, e := range all {
+		ok, skip := r.matches(e)
+		if skip != "" {
+			fmt.Fprintf(os.Stderr, "cloudlab-reaper: skipping %s: %s\n", e.Name, skip)
+		}
+		if !ok {
+			continue
+		}
+		res := result{
+			Name: e.Name, UUID: e.UUID, Status: e.Status,
+			Creator: e.Creator, Created: e.Created, Expires: e.Expires,
+			Action: actionWould,
+		}
+		if !dryRun {
+			if _, err := portalclient.Terminate(cfg.Client, project, e.Name); err != nil {
+				res.Action, res.Error = actionFailed, err.Error()
+			} else {
+				res.Action = actionTerminated
+			}
+		}
+		out = append(out, res)
+	}
+	return out
+}
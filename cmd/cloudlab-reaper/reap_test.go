@@ -0,0 +1,67 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+func TestRulesMatches(t *testing.T) {
+	denver, err := time.LoadLocation("America/Denver")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2026, 10, 19, 18, 0, 0, 0, time.UTC) // 12:00 MDT
+	tests := []struct {
+		name      string
+		patterns  []string
+		olderThan time.Duration
+		expiresIn time.Duration
+		exp       portalclient.ExperimentInfo
+		want      bool
+		wantSkip  bool
+	}{
+		{name: "name only", patterns: []string{"tf-ci-*"},
+			exp: portalclient.ExperimentInfo{Name: "tf-ci-42"}, want: true},
+		{name: "name mismatch", patterns: []string{"tf-ci-*"},
+			exp: portalclient.ExperimentInfo{Name: "thesis"}},
+		{name: "second pattern", patterns: []string{"tf-ci-*", "scratch-?"},
+			exp: portalclient.ExperimentInfo{Name: "scratch-1"}, want: true},
+		{name: "old enough", patterns: []string{"*"}, olderThan: 6 * time.Hour,
+			exp: portalclient.ExperimentInfo{Name: "a", Created: "2026-10-19T05:00:00Z"}, want: true},
+		{name: "too young", patterns: []string{"*"}, olderThan: 6 * time.Hour,
+			exp: portalclient.ExperimentInfo{Name: "a", Created: "2026-10-19T15:00:00Z"}},
+		// 07:00 MDT is 13:00 UTC: five hours old, not eleven.
+		{name: "zoneless in portal zone", patterns: []string{"*"}, olderThan: 6 * time.Hour,
+			exp: portalclient.ExperimentInfo{Name: "a", Created: "2026-10-19 07:00:00"}},
+		{name: "zoneless old enough", patterns: []string{"*"}, olderThan: 6 * time.Hour,
+			exp: portalclient.ExperimentInfo{Name: "a", Created: "2026-10-19 05:00:00"}, want: true},
+		{name: "unparsable created", patterns: []string{"*"}, olderThan: time.Hour,
+			exp: portalclient.ExperimentInfo{Name: "a", Created: "yesterday"}, wantSkip: true},
+		{name: "expiring soon", patterns: []string{"*"}, expiresIn: 2 * time.Hour,
+			exp: portalclient.ExperimentInfo{Name: "a", Expires: "2026-10-19T19:00:00Z"}, want: true},
+		{name: "expiring later", patterns: []string{"*"}, expiresIn: 2 * time.Hour,
+			exp: portalclient.ExperimentInfo{Name: "a", Expires: "2026-10-20T19:00:00Z"}},
+		{name: "unparsable expiry", patterns: []string{"*"}, expiresIn: time.Hour,
+			exp: portalclient.ExperimentInfo{Name: "a"}, wantSkip: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRules(tt.patterns, tt.olderThan, tt.expiresIn, now, denver)
+			got, skip := r.matches(tt.exp)
+			if got != tt.want || (skip != "") != tt.wantSkip {
+				t.Fatalf("matches = %v, %q; want %v, skip %v", got, skip, tt.want, tt.wantSkip)
+			}
+		})
+	}
+}
+
+func TestRulesCheck(t *testing.T) {
+	if err := newRules([]string{"tf-ci-[", "x"}, 0, 0, time.Now(), time.UTC).check(); err == nil {
+		t.Fatal("expected bad pattern error")
+	}
+	if err := newRules([]string{"tf-ci-*"}, 0, 0, time.Now(), time.UTC).check(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,76 @@
+package availability
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/validation"
+)
+
+// DataSource is cloudlab_resource_availability.
+func DataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRead,
+		Schema: map[string]*schema.Schema{
+			// filters
+			"aggregate":     {Type: schema.TypeString, Optional: true},
+			"hardware_type": {Type: schema.TypeString, Optional: true},
+
+			// outputs
+			"resources": {Type: schema.TypeList, Computed: true, Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+				"aggregate":     {Type: schema.TypeString, Computed: true},
+				"site":          {Type: schema.TypeString, Computed: true},
+				"hardware_type": {Type: schema.TypeString, Computed: true},
+				"free":          {Type: schema.TypeInt, Computed: true},
+				"total":         {Type: schema.TypeInt, Computed: true},
+				"reserved":      {Type: schema.TypeInt, Computed: true}, // free but held for reservations
+				"available":     {Type: schema.TypeInt, Computed: true}, // free - reserved
+			}}},
+			// "<site>/<hardware_type>" -> available, e.g. free["utah.cloudlab.us/d430"]
+			"free": {Type: schema.TypeMap, Computed: true, Elem: &schema.Schema{Type: schema.TypeInt}},
+		},
+	}
+}
+
+func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	agg := d.Get("aggregate").(string)
+	hw := d.Get("hardware_type").(string)
+	if !validation.IsValidAggregate(agg) {
+		return diag.Errorf("aggregate %q is not a recognized URN", agg)
+	}
+
+	tflog.Debug(ctx, "querying resource availability", map[string]any{"aggregate": agg, "type": hw})
+	avail, err := portalclient.ResourceAvailability(cfg.Client, agg, hw)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	list := make([]map[string]interface{}, 0, len(avail))
+	free := map[string]int{}
+	for _, a := range avail {
+		if (agg != "" && a.Aggregate != agg) || (hw != "" && a.HardwareType != hw) {
+			continue
+		}
+		site := validation.Aggregates[a.Aggregate]
+		if site == "" {
+			site = a.Aggregate
+		}
+		usable := max(a.Free-a.Reserved, 0)
+		list = append(list, map[string]interface{}{
+			"aggregate": a.Aggregate, "site": site, "hardware_type": a.HardwareType,
+			"free": a.Free, "total": a.Total, "reserved": a.Reserved, "available": usable,
+		})
+		free[site+"/"+a.HardwareType] = usable
+	}
+	_ = d.Set("resources", list)
+	_ = d.Set("free", free)
+	d.SetId(fmt.Sprintf("%s|%s", agg, hw))
+	return nil
+}
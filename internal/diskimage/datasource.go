@@ -0,0 +1,97 @@
+package diskimage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+// DataSource is cloudlab_image: resolves a project image by name to a URN.
+func DataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRead,
+		Schema: map[string]*schema.Schema{
+			"project": {Type: schema.TypeString, Optional: true},
+			"name":    {Type: schema.TypeString, Required: true},
+			"version": {Type: schema.TypeInt, Optional: true, Default: -1, ValidateFunc: validation.IntAtLeast(-1)}, // -1: most recent
+			"os":      {Type: schema.TypeString, Optional: true},                                                    // e.g. "Linux"
+			"arch":    {Type: schema.TypeString, Optional: true},                                                    // e.g. "x86_64"
+
+			// outputs
+			"urn":              {Type: schema.TypeString, Computed: true},
+			"version_urn":      {Type: schema.TypeString, Computed: true},
+			"resolved_version": {Type: schema.TypeInt, Computed: true},
+			"created":          {Type: schema.TypeString, Computed: true},
+		},
+	}
+}
+
+// selectImage returns the requested version (version >= 0) or the newest
+// ready version matching the OS/architecture filters; versions still being
+// captured or that failed are never picked as latest.
+func selectImage(all []portalclient.Image, name string, version int, os, arch string) (*portalclient.Image, error) {
+	var best *portalclient.Image
+	for i := range all {
+		im := &all[i]
+		if !strings.EqualFold(im.Name, name) {
+			continue
+		}
+		if (os != "" && !strings.EqualFold(im.OS, os)) || (arch != "" && !strings.EqualFold(im.Arch, arch)) {
+			continue
+		}
+		if version >= 0 {
+			if im.Version == version {
+				return im, nil
+			}
+			continue
+		}
+		if !strings.EqualFold(strings.TrimSpace(im.Status), imageReady) {
+			continue
+		}
+		if best == nil || im.Version > best.Version {
+			best = im
+		}
+	}
+	if best == nil {
+		if version >= 0 {
+			return nil, fmt.Errorf("image %q has no version %d matching os=%q arch=%q", name, version, os, arch)
+		}
+		return nil, fmt.Errorf("no ready image %q matching os=%q arch=%q", name, os, arch)
+	}
+	return best, nil
+}
+
+func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	project := d.Get("project").(string)
+	if project == "" {
+		project = cfg.Project
+	}
+	name := d.Get("name").(string)
+	version := d.Get("version").(int)
+
+	tflog.Debug(ctx, "looking up image", map[string]any{"project": project, "image": name, "version": version})
+	all, err := portalclient.ListImages(cfg.Client, project, name)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	im, err := selectImage(all, name, version, d.Get("os").(string), d.Get("arch").(string))
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	d.SetId(im.VersionURN)
+	_ = d.Set("urn", im.URN)
+	_ = d.Set("version_urn", im.VersionURN)
+	_ = d.Set("resolved_version", im.Version)
+	_ = d.Set("created", im.Created)
+	return nil
+}
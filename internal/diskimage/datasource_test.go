@@ -0,0 +1,48 @@
+package diskimage
+
+import (
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+func TestSelectImage(t *testing.T) {
+	all := []portalclient.Image{
+		{Name: "ubuntu22-base", Version: 0, OS: "Linux", Arch: "x86_64", VersionURN: "u:0", Status: "ready"},
+		{Name: "ubuntu22-base", Version: 2, OS: "Linux", Arch: "x86_64", VersionURN: "u:2", Status: "ready"},
+		{Name: "ubuntu22-base", Version: 3, OS: "Linux", Arch: "aarch64", VersionURN: "u:3", Status: "Ready"},
+		{Name: "ubuntu22-base", Version: 4, OS: "Linux", Arch: "x86_64", VersionURN: "u:4", Status: "imaging"},
+		{Name: "ubuntu22-base", Version: 5, OS: "Linux", Arch: "aarch64", VersionURN: "u:5", Status: "failed"},
+		{Name: "other", Version: 9, OS: "Linux", Arch: "x86_64", VersionURN: "o:9", Status: "ready"},
+		{Name: "wip", Version: 1, OS: "Linux", Arch: "x86_64", VersionURN: "w:1", Status: "imaging"},
+	}
+	tests := []struct {
+		name     string
+		image    string
+		version  int
+		os, arch string
+		want     string
+		wantErr  bool
+	}{
+		{name: "latest", image: "ubuntu22-base", version: -1, want: "u:3"},
+		{name: "latest for arch", image: "ubuntu22-base", version: -1, arch: "x86_64", want: "u:2"},
+		{name: "version zero", image: "ubuntu22-base", version: 0, want: "u:0"},
+		{name: "case insensitive", image: "Ubuntu22-Base", version: 2, os: "linux", want: "u:2"},
+		{name: "missing version", image: "ubuntu22-base", version: 1, wantErr: true},
+		{name: "version on other arch", image: "ubuntu22-base", version: 3, arch: "x86_64", wantErr: true},
+		{name: "unknown image", image: "centos", version: -1, wantErr: true},
+		{name: "only version still imaging", image: "wip", version: -1, wantErr: true},
+		{name: "pinned version not ready", image: "ubuntu22-base", version: 4, want: "u:4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := selectImage(all, tt.image, tt.version, tt.os, tt.arch)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.VersionURN != tt.want {
+				t.Fatalf("got %s, want %s", got.VersionURN, tt.want)
+			}
+		})
+	}
+}
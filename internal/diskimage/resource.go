@@ -0,0 +1,122 @@
+package diskimage
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+const imageReady = "ready"
+
+// Snapshots the node and waits until the new image version is ready.
+func resourceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	project := d.Get("project").(string)
+	if project == "" {
+		project = cfg.Project
+	}
+	req := portalclient.ImageRequest{
+		Project:    project,
+		Experiment: d.Get("experiment").(string),
+		Node:       d.Get("node").(string),
+		ImageName:  d.Get("name").(string),
+		ImageProj:  project,
+		Global:     d.Get("global").(bool),
+		Shared:     d.Get("shared").(bool),
+	}
+	tflog.Info(ctx, "creating disk image", map[string]any{
+		"project": project, "experiment": req.Experiment, "node": req.Node, "image": req.ImageName,
+	})
+	im, err := portalclient.CreateImage(cfg.Client, req)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	// The unversioned URN follows the newest version, whoever made it; this
+	// resource owns exactly the version it created.
+	if im.VersionURN == "" {
+		return diag.Errorf("portal did not return a version URN for new image %q (%s); it may need deleting by hand", req.ImageName, im.URN)
+	}
+	d.SetId(im.VersionURN)
+
+	to := d.Timeout(schema.TimeoutCreate)
+	if to == 0 {
+		to = 60 * time.Minute
+	}
+	stateConf := &retry.StateChangeConf{
+		Pending:    []string{"", "imaging", "copying", "pending", "creating"},
+		Target:     []string{imageReady},
+		Timeout:    to,
+		Delay:      30 * time.Second,
+		MinTimeout: 15 * time.Second,
+		Refresh: func() (interface{}, string, error) {
+			cur, err := portalclient.ImageInfo(cfg.Client, im.VersionURN)
+			if err != nil {
+				tflog.Warn(ctx, "image status fetch failed; retrying", map[string]any{"error": err})
+				return nil, "", nil
+			}
+			return cur, strings.ToLower(strings.TrimSpace(cur.Status)), nil
+		},
+	}
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return diag.Errorf("waiting for image %q to become ready: %v", im.VersionURN, err)
+	}
+	return resourceRead(ctx, d, meta)
+}
+
+// Reads the version this resource created; the ID is its version URN.
+func resourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	if d.Id() == "" {
+		return nil
+	}
+	im, err := portalclient.ImageInfo(cfg.Client, d.Id())
+	if portalclient.IsNotFound(nil, err) {
+		tflog.Warn(ctx, "image not found during read; clearing state",
+			map[string]any{"urn": d.Id(), "error": err})
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	_ = d.Set("urn", im.URN)
+	_ = d.Set("version", im.Version)
+	_ = d.Set("version_urn", im.VersionURN)
+	_ = d.Set("status", im.Status)
+	_ = d.Set("created", im.Created)
+	return nil
+}
+
+// Only delete_behavior is updatable, and it lives in state alone.
+func resourceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	return resourceRead(ctx, d, meta)
+}
+
+// With delete_behavior = "delete", deletes only this resource's version.
+func resourceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	if d.Id() == "" {
+		return nil
+	}
+	if d.Get("delete_behavior").(string) != DeleteImage {
+		tflog.Info(ctx, "retaining disk image on the portal", map[string]any{"urn": d.Id()})
+		d.SetId("")
+		return nil
+	}
+	tflog.Info(ctx, "deleting disk image", map[string]any{"urn": d.Id()})
+	if _, err := portalclient.DeleteImage(cfg.Client, d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("")
+	return nil
+}
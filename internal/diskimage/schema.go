@@ -0,0 +1,48 @@
+package diskimage
+
+import (
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+const (
+	DeleteRetain = "retain" // forget the image, keep it on the portal
+	DeleteImage  = "delete" // delete the image from the portal
+)
+
+// Resource is cloudlab_disk_image: a snapshot of one experiment node.
+func Resource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCreate,
+		ReadContext:   resourceRead,
+		UpdateContext: resourceUpdate,
+		DeleteContext: resourceDelete,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(60 * time.Minute),
+		},
+		Schema: map[string]*schema.Schema{
+			"experiment": {Type: schema.TypeString, Required: true, ForceNew: true},
+			"node":       {Type: schema.TypeString, Required: true, ForceNew: true},
+			"name":       {Type: schema.TypeString, Required: true, ForceNew: true},
+			"project":    {Type: schema.TypeString, Optional: true, ForceNew: true},
+			"global":     {Type: schema.TypeBool, Optional: true, Default: false, ForceNew: true},
+			"shared":     {Type: schema.TypeBool, Optional: true, Default: false, ForceNew: true},
+
+			"delete_behavior": {
+				Type:         schema.TypeString,
+				Optional:     true,
+				Default:      DeleteRetain,
+				ValidateFunc: validation.StringInSlice([]string{DeleteRetain, DeleteImage}, false),
+			},
+
+			// outputs
+			"urn":         {Type: schema.TypeString, Computed: true},
+			"version":     {Type: schema.TypeInt, Computed: true},
+			"version_urn": {Type: schema.TypeString, Computed: true},
+			"status":      {Type: schema.TypeString, Computed: true},
+			"created":     {Type: schema.TypeString, Computed: true},
+		},
+	}
+}
@@ -0,0 +1,212 @@
+package experiment
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+// parseIfaceAddr accepts "a.b.c.d" plus a dotted netmask, or "a.b.c.d/len".
+// With neither a netmask nor a prefix length it falls back to the subnet's.
+func parseIfaceAddr(ip, netmask string, subnet netip.Prefix) (netip.Prefix, error) {
+	ip = strings.TrimSpace(ip)
+	if strings.Contains(ip, "/") {
+		if netmask != "" {
+			return netip.Prefix{}, fmt.Errorf("ip %q is in CIDR form; do not also set netmask", ip)
+		}
+		p, err := netip.ParsePrefix(ip)
+		if err != nil || !p.Addr().Is4() || p.Bits() > 32 {
+			return netip.Prefix{}, fmt.Errorf("ip %q is not an IPv4 address", ip)
+		}
+		return p, nil
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || !addr.Is4() {
+		return netip.Prefix{}, fmt.Errorf("ip %q is not an IPv4 address", ip)
+	}
+	if netmask == "" {
+		if !subnet.IsValid() {
+			return netip.Prefix{}, fmt.Errorf("ip %q needs a netmask, a /prefix, or a subnet on its link", ip)
+		}
+		return netip.PrefixFrom(addr, subnet.Bits()), nil
+	}
+	bits, err := maskBits(netmask)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, bits), nil
+}
+
+func maskBits(netmask string) (int, error) {
+	m, err := netip.ParseAddr(strings.TrimSpace(netmask))
+	if err != nil || !m.Is4() {
+		return 0, fmt.Errorf("netmask %q is not a dotted IPv4 mask", netmask)
+	}
+	b := m.As4()
+	v := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	bits := 0
+	for v&0x80000000 != 0 {
+		bits++
+		v <<= 1
+	}
+	if v != 0 {
+		return 0, fmt.Errorf("netmask %q is not contiguous", netmask)
+	}
+	return bits, nil
+}
+
+func bitsToMask(bits int) string {
+	v := ^uint32(0) << (32 - bits)
+	if bits == 0 {
+		v = 0
+	}
+	return fmt.Sprintf("%d.%d.%d.%d", v>>24, v>>16&0xff, v>>8&0xff, v&0xff)
+}
+
+func parseSubnet(l model.Link) (netip.Prefix, error) {
+	if l.Subnet == "" {
+		return netip.Prefix{}, nil
+	}
+	p, err := netip.ParsePrefix(strings.TrimSpace(l.Subnet))
+	if err != nil || !p.Addr().Is4() {
+		return netip.Prefix{}, fmt.Errorf("%s %q subnet %q is not an IPv4 CIDR", l.Kind, l.Name, l.Subnet)
+	}
+	if p.Masked() != p {
+		return netip.Prefix{}, fmt.Errorf("%s %q subnet %q has host bits set (did you mean %s?)", l.Kind, l.Name, l.Subnet, p.Masked())
+	}
+	return p, nil
+}
+
+// validateAddressing checks explicit addresses and subnets: every address
+// parses, sits inside its link's subnet, is not the network or broadcast
+// address, and is unique; link networks must not overlap each other.
+func validateAddressing(s model.ExperimentSpec) error {
+	seen := map[netip.Addr]string{}
+	type linkNet struct {
+		name string
+		net  netip.Prefix
+	}
+	var nets []linkNet
+
+	for _, l := range s.Links {
+		subnet, err := parseSubnet(l)
+		if err != nil {
+			return err
+		}
+		var lnet netip.Prefix
+		if subnet.IsValid() {
+			lnet = subnet
+		}
+		for _, ifc := range l.Interfaces {
+			if ifc.IP == "" {
+				if ifc.Netmask != "" {
+					return fmt.Errorf("%s %q interface on %q sets netmask without ip", l.Kind, l.Name, ifc.Node)
+				}
+				continue
+			}
+			p, err := parseIfaceAddr(ifc.IP, ifc.Netmask, subnet)
+			if err != nil {
+				return fmt.Errorf("%s %q interface on %q: %w", l.Kind, l.Name, ifc.Node, err)
+			}
+			if subnet.IsValid() && (!subnet.Contains(p.Addr()) || p.Bits() != subnet.Bits()) {
+				return fmt.Errorf("%s %q interface on %q: %s is outside subnet %s", l.Kind, l.Name, ifc.Node, p, subnet)
+			}
+			if !lnet.IsValid() {
+				lnet = p.Masked()
+			} else if p.Masked() != lnet {
+				return fmt.Errorf("%s %q interface on %q: %s is not on the same network as %s", l.Kind, l.Name, ifc.Node, p, lnet)
+			}
+			if p.Bits() < 31 && (p.Addr() == p.Masked().Addr() || p.Addr() == lastAddr(p)) {
+				return fmt.Errorf("%s %q interface on %q: %s is a network or broadcast address", l.Kind, l.Name, ifc.Node, p)
+			}
+			if other, dup := seen[p.Addr()]; dup {
+				return fmt.Errorf("duplicate address %s (%s and %s %q on %q)", p.Addr(), other, l.Kind, l.Name, ifc.Node)
+			}
+			seen[p.Addr()] = fmt.Sprintf("%s %q on %q", l.Kind, l.Name, ifc.Node)
+		}
+		if lnet.IsValid() {
+			for _, o := range nets {
+				if o.net.Overlaps(lnet) {
+					return fmt.Errorf("%s %q network %s overlaps %q network %s", l.Kind, l.Name, lnet, o.name, o.net)
+				}
+			}
+			nets = append(nets, linkNet{name: l.Name, net: lnet})
+		}
+	}
+	return nil
+}
+
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Masked().Addr().As4()
+	v := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	v |= ^(^uint32(0) << (32 - p.Bits()))
+	return netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+}
+
+// assignAddresses normalizes explicit addresses to ip+netmask and allocates
+// addresses from the link subnet, in interface order, for interfaces without
+// one. Run after validateSpec.
+func assignAddresses(s *model.ExperimentSpec) error {
+	for li := range s.Links {
+		l := &s.Links[li]
+		subnet, err := parseSubnet(*l)
+		if err != nil {
+			return err
+		}
+		used := map[netip.Addr]bool{}
+		for i := range l.Interfaces {
+			ifc := &l.Interfaces[i]
+			if ifc.IP == "" {
+				continue
+			}
+			p, err := parseIfaceAddr(ifc.IP, ifc.Netmask, subnet)
+			if err != nil {
+				return err
+			}
+			ifc.IP, ifc.Netmask = p.Addr().String(), bitsToMask(p.Bits())
+			used[p.Addr()] = true
+		}
+		if !subnet.IsValid() {
+			continue
+		}
+		next := subnet.Addr()
+		if subnet.Bits() < 31 {
+			next = next.Next() // skip the network address; /31 and /32 have none (RFC 3021)
+		}
+		for i := range l.Interfaces {
+			ifc := &l.Interfaces[i]
+			if ifc.IP != "" {
+				continue
+			}
+			for used[next] {
+				next = next.Next()
+			}
+			if !subnet.Contains(next) || (subnet.Bits() < 31 && next == lastAddr(subnet)) {
+				return fmt.Errorf("%s %q subnet %s has no free address for %q", l.Kind, l.Name, subnet, ifc.Node)
+			}
+			ifc.IP, ifc.Netmask = next.String(), bitsToMask(subnet.Bits())
+			used[next] = true
+		}
+	}
+	return nil
+}
+
+// flattenAddresses maps "<link>/<node>" to "ip/len" for addressed interfaces.
+func flattenAddresses(s model.ExperimentSpec) map[string]string {
+	out := map[string]string{}
+	for _, l := range s.Links {
+		for _, ifc := range l.Interfaces {
+			if ifc.IP == "" {
+				continue
+			}
+			bits, err := maskBits(ifc.Netmask)
+			if err != nil {
+				continue
+			}
+			out[l.Name+"/"+ifc.Node] = fmt.Sprintf("%s/%d", ifc.IP, bits)
+		}
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package experiment
+
+import (
+	"net/netip"
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+func TestParseIfaceAddr(t *testing.T) {
+	subnet := netip.MustParsePrefix("10.1.0.0/24")
+	tests := []struct {
+		name        string
+		ip, netmask string
+		subnet      netip.Prefix
+		want        string
+		wantErr     bool
+	}{
+		{name: "ipv4 cidr", ip: "10.1.0.5/24", want: "10.1.0.5/24"},
+		{name: "ipv4 netmask", ip: "10.1.0.5", netmask: "255.255.255.0", want: "10.1.0.5/24"},
+		{name: "subnet fallback", ip: "10.1.0.5", subnet: subnet, want: "10.1.0.5/24"},
+		{name: "ipv6 cidr", ip: "fe80::1/64", wantErr: true},
+		{name: "ipv6 address", ip: "fe80::1", netmask: "255.255.255.0", wantErr: true},
+		{name: "cidr and netmask", ip: "10.1.0.5/24", netmask: "255.255.255.0", wantErr: true},
+		{name: "bad netmask", ip: "10.1.0.5", netmask: "255.0.255.0", wantErr: true},
+		{name: "no mask or subnet", ip: "10.1.0.5", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseIfaceAddr(tt.ip, tt.netmask, tt.subnet)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Fatalf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaskBits(t *testing.T) {
+	tests := []struct {
+		mask    string
+		want    int
+		wantErr bool
+	}{
+		{mask: "255.255.255.0", want: 24},
+		{mask: "255.255.255.255", want: 32},
+		{mask: "0.0.0.0", want: 0},
+		{mask: "255.255.0.255", wantErr: true},
+		{mask: "ffff::", wantErr: true},
+		{mask: "bogus", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mask, func(t *testing.T) {
+			got, err := maskBits(tt.mask)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && got != tt.want {
+				t.Fatalf("got %d, want %d", got, tt.want)
+			}
+			if err == nil && bitsToMask(got) != tt.mask {
+				t.Fatalf("bitsToMask(%d) = %s", got, bitsToMask(got))
+			}
+		})
+	}
+}
+
+func TestAssignAddresses(t *testing.T) {
+	spec := model.ExperimentSpec{Links: []model.Link{{
+		Kind: "lan", Name: "lan0", Subnet: "10.1.0.0/29",
+		Interfaces: []model.Iface{
+			{Node: "a"},
+			{Node: "b", IP: "10.1.0.1/29"},
+			{Node: "c"},
+		},
+	}}}
+	if err := assignAddresses(&spec); err != nil {
+		t.Fatal(err)
+	}
+	got := flattenAddresses(spec)
+	want := map[string]string{"lan0/a": "10.1.0.2/29", "lan0/b": "10.1.0.1/29", "lan0/c": "10.1.0.3/29"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+
+	p2p := model.ExperimentSpec{Links: []model.Link{{
+		Kind: "link", Name: "p2p", Subnet: "10.3.0.0/31",
+		Interfaces: []model.Iface{{Node: "a"}, {Node: "b"}},
+	}}}
+	if err := assignAddresses(&p2p); err != nil {
+		t.Fatalf("/31: %v", err)
+	}
+	got = flattenAddresses(p2p)
+	if got["p2p/a"] != "10.3.0.0/31" || got["p2p/b"] != "10.3.0.1/31" {
+		t.Errorf("/31 addresses = %v", got)
+	}
+
+	full := model.ExperimentSpec{Links: []model.Link{{
+		Kind: "link", Name: "l0", Subnet: "10.2.0.0/30",
+		Interfaces: []model.Iface{{Node: "a"}, {Node: "b"}, {Node: "c"}},
+	}}}
+	if err := assignAddresses(&full); err == nil {
+		t.Fatal("expected exhausted subnet error")
+	}
+
+	v6 := model.ExperimentSpec{Links: []model.Link{{
+		Kind: "link", Name: "l6",
+		Interfaces: []model.Iface{{Node: "a", IP: "fe80::1/64"}, {Node: "b"}},
+	}}}
+	if err := assignAddresses(&v6); err == nil {
+		t.Fatal("expected IPv6 address to be rejected")
+	}
+}
@@ -1,15 +1,95 @@
 package experiment
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
 
-func composeParams(project, name, specJSON string) map[string]any {
-	bindingsMap := map[string]string{profileParamSpecJSON: specJSON}
-	b, _ := json.Marshal(bindingsMap)
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
-	return map[string]any{
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+// startOptions are optional startExperiment parameters beyond the spec.
+type startOptions struct {
+	Reservation string    // cloudlab_reservation uuid to bind to
+	Start       time.Time // scheduled start; zero starts now
+	Stop        time.Time // fixed end; zero uses Duration or the portal default
+	Duration    int       // hours
+}
+
+func expandStartOptions(d *schema.ResourceData, now time.Time) (startOptions, error) {
+	opts := startOptions{
+		Reservation: d.Get("reservation_id").(string),
+		Duration:    d.Get("duration_hours").(int),
+	}
+	if v := d.Get("start_at").(string); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return opts, fmt.Errorf("start_at: %w", err)
+		}
+		if !t.After(now) {
+			return opts, fmt.Errorf("start_at %s is not in the future", v)
+		}
+		opts.Start = t
+	}
+	if v := d.Get("stop_at").(string); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return opts, fmt.Errorf("stop_at: %w", err)
+		}
+		from := now
+		if !opts.Start.IsZero() {
+			from = opts.Start
+		}
+		if !t.After(from) {
+			return opts, fmt.Errorf("stop_at %s must be after the experiment starts", v)
+		}
+		opts.Stop = t
+	}
+	return opts, nil
+}
+
+// encodeBindings wraps the spec as profile bindings: a JSON object as
+// STRING (required by portal API).
+func encodeBindings(specJSON string) string {
+	b, _ := json.Marshal(map[string]string{profileParamSpecJSON: specJSON})
+	return string(b)
+}
+
+// validateScheduledSpec rejects settings that need running nodes at create
+// time, since a scheduled experiment has none until start_at.
+func validateScheduledSpec(spec model.ExperimentSpec, opts startOptions) error {
+	if opts.Start.IsZero() {
+		return nil
+	}
+	for _, n := range spec.Nodes {
+		if n.PowerState == PowerOff {
+			return fmt.Errorf("node %q: power_state %q cannot be applied to an experiment scheduled with start_at", n.Name, PowerOff)
+		}
+	}
+	return nil
+}
+
+func composeParams(project, name, specJSON string, opts startOptions) map[string]any {
+	params := map[string]any{
 		"proj":     project,
 		"profile":  profileName,
 		"name":     name,
-		"bindings": string(b), // JSON object as STRING (required by portal API)
+		"bindings": encodeBindings(specJSON),
+	}
+	if opts.Reservation != "" {
+		params["reservation"] = opts.Reservation
+	}
+	// The portal takes times as epoch seconds and duration in hours.
+	if !opts.Start.IsZero() {
+		params["start"] = opts.Start.Unix()
+	}
+	if !opts.Stop.IsZero() {
+		params["stop"] = opts.Stop.Unix()
+	}
+	if opts.Duration > 0 {
+		params["duration"] = opts.Duration
 	}
+	return params
 }
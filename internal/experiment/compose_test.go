@@ -0,0 +1,36 @@
+package experiment
+
+import (
+	"testing"
+	"time"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+func TestValidateScheduledSpec(t *testing.T) {
+	spec := model.ExperimentSpec{Nodes: []model.Node{{Name: "a", PowerState: PowerOn}, {Name: "b", PowerState: PowerOff}}}
+	if err := validateScheduledSpec(spec, startOptions{}); err != nil {
+		t.Fatalf("immediate start: %v", err)
+	}
+	if err := validateScheduledSpec(spec, startOptions{Start: time.Now().Add(48 * time.Hour)}); err == nil {
+		t.Fatal("expected power_state off to be rejected for a scheduled start")
+	}
+	spec.Nodes[1].PowerState = PowerOn
+	if err := validateScheduledSpec(spec, startOptions{Start: time.Now().Add(48 * time.Hour)}); err != nil {
+		t.Fatalf("scheduled start: %v", err)
+	}
+}
+
+func TestComposeParamsSchedule(t *testing.T) {
+	start := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	p := composeParams("proj", "exp", "{}", startOptions{Start: start, Duration: 6, Reservation: "r-1"})
+	if p["start"] != start.Unix() || p["duration"] != 6 || p["reservation"] != "r-1" {
+		t.Fatalf("params = %v", p)
+	}
+	if _, ok := p["stop"]; ok {
+		t.Fatalf("unexpected stop in %v", p)
+	}
+	if p["bindings"] != `{"spec_json":"{}"}` {
+		t.Fatalf("bindings = %v", p["bindings"])
+	}
+}
@@ -14,6 +14,15 @@ const (
 	StatusBooting      = "booting"
 	StatusBooted       = "booted"
 	StatusReady        = "ready"
+	StatusFailed       = "failed"
+
+	// Scheduled experiments (start_at) sit in these before provisioning.
+	StatusScheduled = "scheduled"
+	StatusWaiting   = "waiting"
+
+	// StatusStartup is a wait target only: ready, and every node's startup
+	// (execute) services have exited.
+	StatusStartup = "startup"
 )
 
 var statusOrder = []string{
@@ -0,0 +1,75 @@
+package experiment
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/validation"
+)
+
+// urn:publicid:IDN+<site>[:<project>]+<ltdataset|imdataset|dataset>+<name>
+var datasetURN = regexp.MustCompile(`^urn:publicid:IDN\+([^+:]+)(?::[^+]+)?\+(ltdataset|imdataset|dataset)\+[^+]+$`)
+
+// Long-term datasets are served over the experiment network by a remote
+// blockstore; image-backed datasets are unpacked onto a local blockstore.
+func isRemoteDataset(urn string) bool {
+	m := datasetURN.FindStringSubmatch(urn)
+	return m != nil && m[2] != "imdataset"
+}
+
+// expandRemoteDatasets moves every long-term dataset blockstore off its node
+// onto a remote_blockstore node named "<node>-<blockstore>", linked to the
+// node by a best-effort, VLAN-tagged link as CloudLab recommends.
+func expandRemoteDatasets(spec *model.ExperimentSpec) {
+	var added []model.Node
+	for i := range spec.Nodes {
+		n := &spec.Nodes[i]
+		var local []model.Blockstore
+		for _, b := range n.Blockstores {
+			if !isRemoteDataset(b.Dataset) {
+				local = append(local, b)
+				continue
+			}
+			rname := n.Name + "-" + b.Name
+			added = append(added, model.Node{
+				Kind:        "remote_blockstore",
+				Name:        rname,
+				Aggregate:   n.Aggregate,
+				Blockstores: []model.Blockstore{b},
+			})
+			on := true
+			spec.Links = append(spec.Links, model.Link{
+				Kind:             "link",
+				Name:             rname + "-link",
+				Interfaces:       []model.Iface{{Node: n.Name}, {Node: rname}},
+				BestEffort:       &on,
+				VlanTagging:      &on,
+				LinkMultiplexing: &on,
+			})
+		}
+		n.Blockstores = local
+	}
+	spec.Nodes = append(spec.Nodes, added...)
+}
+
+func validateDatasetBlockstore(n model.Node, b model.Blockstore) error {
+	m := datasetURN.FindStringSubmatch(b.Dataset)
+	if m == nil {
+		return fmt.Errorf("node %q blockstore %q dataset %q is not a dataset URN", n.Name, b.Name, b.Dataset)
+	}
+	if b.Size != 0 {
+		return fmt.Errorf("node %q blockstore %q: size_gb cannot be set on a dataset-backed blockstore", n.Name, b.Name)
+	}
+	if strings.TrimSpace(b.Mount) == "" {
+		return fmt.Errorf("node %q blockstore %q: mount is required for a dataset", n.Name, b.Name)
+	}
+	if n.Kind == "remote_blockstore" && b.Placement != "" {
+		return fmt.Errorf("node %q blockstore %q: placement applies only to local blockstores", n.Name, b.Name)
+	}
+	if n.Aggregate != "" && validation.Aggregates[n.Aggregate] != m[1] {
+		return fmt.Errorf("node %q blockstore %q: dataset is on %s, not on the node's aggregate", n.Name, b.Name, m[1])
+	}
+	return nil
+}
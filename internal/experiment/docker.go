@@ -0,0 +1,33 @@
+package experiment
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+func validateDocker(n model.Node) error {
+	sources := 0
+	for _, v := range []string{n.DockerImage, n.Dockerfile, n.DiskImage} {
+		if v != "" {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("docker %q: set only one of image, dockerfile or disk_image", n.Name)
+	}
+	if n.Dockerfile != "" {
+		u, err := url.Parse(n.Dockerfile)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("docker %q dockerfile must be an http(s) URL (got %q)", n.Name, n.Dockerfile)
+		}
+	}
+	if n.Cores != nil && *n.Cores < 1 {
+		return fmt.Errorf("docker %q cores must be >= 1", n.Name)
+	}
+	if n.RamMB != nil && *n.RamMB < 1 {
+		return fmt.Errorf("docker %q ram_mb must be >= 1", n.Name)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package experiment
+
+import "testing"
+
+func TestValidateDockerResources(t *testing.T) {
+	tests := []struct {
+		name    string
+		tmpl    map[string]interface{}
+		wantErr bool
+	}{
+		{name: "unset", tmpl: map[string]interface{}{"cores": 0, "ram_mb": 0}},
+		{name: "valid", tmpl: map[string]interface{}{"cores": 2, "ram_mb": 2048}},
+		{name: "negative cores", tmpl: map[string]interface{}{"cores": -1}, wantErr: true},
+		{name: "negative ram", tmpl: map[string]interface{}{"ram_mb": -512}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := templateNode("docker", "d0", tt.tmpl)
+			if err := validateDocker(n); (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
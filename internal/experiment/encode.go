@@ -7,6 +7,7 @@ import (
 )
 
 func encodeSpec(spec model.ExperimentSpec) (string, error) {
+	spec.Links = applyShaping(spec)
 	b, err := json.Marshal(spec)
 	if err != nil {
 		return "", err
@@ -2,56 +2,140 @@ package experiment
 
 import (
 	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
-	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
 
-func buildSpec(d *schema.ResourceData) model.ExperimentSpec {
+// specSource is satisfied by *schema.ResourceData, *schema.ResourceDiff and
+// priorState, so the same expansion serves create, plan and update.
+type specSource interface {
+	Get(key string) interface{}
+}
+
+// priorState reads the old side of every change.
+type priorState struct {
+	d interface {
+		GetChange(key string) (interface{}, interface{})
+	}
+}
+
+func (p priorState) Get(key string) interface{} {
+	o, _ := p.d.GetChange(key)
+	return o
+}
+
+func buildSpec(d specSource) (model.ExperimentSpec, error) {
 	var spec model.ExperimentSpec
 	// rawpc
 	for _, v := range toList(d.Get("rawpc")) {
 		m := v.(map[string]interface{})
 		spec.Nodes = append(spec.Nodes, model.Node{
-			Kind:         "rawpc",
-			Name:         s(m["name"]),
-			HardwareType: s(m["hardware_type"]),
-			Exclusive:    pBool(m, "exclusive"),
-			DiskImage:    s(m["disk_image"]),
-			Aggregate:    s(m["aggregate"]),
-			RoutableIP:   pBool(m, "routable_ip"),
-			Blockstores:  expandBlockstores(m["blockstore"]),
+			Kind:                "rawpc",
+			Name:                s(m["name"]),
+			HardwareType:        s(m["hardware_type"]),
+			ComponentID:         s(m["component_id"]),
+			Exclusive:           pBool(m, "exclusive"),
+			DiskImage:           s(m["disk_image"]),
+			Aggregate:           s(m["aggregate"]),
+			AggregatePreference: toStrings(m["aggregate_preference"]),
+			RoutableIP:          pBool(m, "routable_ip"),
+			Blockstores:         expandBlockstores(m["blockstore"]),
+			Execute:             expandExecute(m["execute"]),
+			Install:             expandInstall(m["install"]),
+			PowerState:          s(m["power_state"]),
+			RebootTriggers:      toStringMap(m["reboot_triggers"]),
 		})
 	}
 	// xenvm
 	for _, v := range toList(d.Get("xenvm")) {
 		m := v.(map[string]interface{})
 		spec.Nodes = append(spec.Nodes, model.Node{
-			Kind:          "xenvm",
-			Name:          s(m["name"]),
-			Cores:         pInt(m, "cores"),
-			RamMB:         pInt(m, "ram_mb"),
-			DiskGB:        pInt(m, "disk_gb"),
-			InstantiateOn: s(m["instantiate_on"]),
-			DiskImage:     s(m["disk_image"]),
-			Aggregate:     s(m["aggregate"]),
-			RoutableIP:    pBool(m, "routable_ip"),
-			Blockstores:   expandBlockstores(m["blockstore"]),
+			Kind:                "xenvm",
+			Name:                s(m["name"]),
+			Cores:               pInt(m, "cores"),
+			RamMB:               pInt(m, "ram_mb"),
+			DiskGB:              pInt(m, "disk_gb"),
+			InstantiateOn:       s(m["instantiate_on"]),
+			DiskImage:           s(m["disk_image"]),
+			Aggregate:           s(m["aggregate"]),
+			AggregatePreference: toStrings(m["aggregate_preference"]),
+			RoutableIP:          pBool(m, "routable_ip"),
+			Blockstores:         expandBlockstores(m["blockstore"]),
+			Execute:             expandExecute(m["execute"]),
+			Install:             expandInstall(m["install"]),
+			PowerState:          s(m["power_state"]),
+			RebootTriggers:      toStringMap(m["reboot_triggers"]),
+		})
+	}
+	// docker
+	for _, v := range toList(d.Get("docker")) {
+		m := v.(map[string]interface{})
+		spec.Nodes = append(spec.Nodes, model.Node{
+			Kind:                "docker",
+			Name:                s(m["name"]),
+			DockerImage:         s(m["image"]),
+			Dockerfile:          s(m["dockerfile"]),
+			DiskImage:           s(m["disk_image"]),
+			Exclusive:           pBool(m, "exclusive"),
+			Cores:               pNonZeroInt(m, "cores"),
+			RamMB:               pNonZeroInt(m, "ram_mb"),
+			InstantiateOn:       s(m["instantiate_on"]),
+			ExecOnStart:         s(m["exec_on_start"]),
+			Aggregate:           s(m["aggregate"]),
+			AggregatePreference: toStrings(m["aggregate_preference"]),
 		})
 	}
 	// links
 	spec.Links = append(spec.Links, expandLinks("link", d.Get("link"))...)
-	spec.Links = append(spec.Links, expandLinks("lan", d.Get("lan"))...)
+	spec.Links = append(spec.Links, expandLans(d.Get("lan"))...)
 	spec.Links = append(spec.Links, expandBridged(d.Get("bridged_link"))...)
-	return spec
+	spec.SSHKeys = toStrings(d.Get("ssh_public_keys"))
+	// node groups (after links so group members can join a declared lan)
+	expandNodeGroups(&spec, d.Get("node_group"))
+	// generated topologies
+	if err := expandTopologies(&spec, d.Get("topology")); err != nil {
+		return spec, err
+	}
+	// long-term datasets become remote blockstore nodes (last, so every
+	// generated node is covered)
+	expandRemoteDatasets(&spec)
+	return spec, nil
 }
 
 func expandBlockstores(v interface{}) []model.Blockstore {
 	var out []model.Blockstore
 	for _, it := range toList(v) {
 		m := it.(map[string]interface{})
+		size, _ := m["size_gb"].(int)
 		out = append(out, model.Blockstore{
-			Name:  s(m["name"]),
-			Mount: s(m["mount"]),
-			Size:  m["size_gb"].(int),
+			Name:      s(m["name"]),
+			Mount:     s(m["mount"]),
+			Size:      size,
+			Dataset:   s(m["dataset"]),
+			ReadOnly:  pTrue(m, "readonly"),
+			Placement: s(m["placement"]),
+		})
+	}
+	return out
+}
+
+func expandExecute(v interface{}) []model.Execute {
+	var out []model.Execute
+	for _, it := range toList(v) {
+		m := it.(map[string]interface{})
+		out = append(out, model.Execute{
+			Shell:   s(m["shell"]),
+			Command: s(m["command"]),
+		})
+	}
+	return out
+}
+
+func expandInstall(v interface{}) []model.Install {
+	var out []model.Install
+	for _, it := range toList(v) {
+		m := it.(map[string]interface{})
+		out = append(out, model.Install{
+			URL:  s(m["url"]),
+			Path: s(m["path"]),
 		})
 	}
 	return out
@@ -62,10 +146,26 @@ func expandLinks(kind string, v interface{}) []model.Link {
 	for _, it := range toList(v) {
 		m := it.(map[string]interface{})
 		out = append(out, model.Link{
-			Kind:       kind,
-			Name:       s(m["name"]),
-			Interfaces: expandIfaces(m["interface"]),
+			Kind:           kind,
+			Name:           s(m["name"]),
+			Interfaces:     expandIfaces(m["interface"]),
+			Bandwidth:      pNonZeroInt(m, "bandwidth_mbps"),
+			Latency:        pNonZeroInt(m, "latency_ms"),
+			Plr:            pNonZeroFloat(m, "plr"),
+			EndnodeShaping: pTrue(m, "endnode_shaping"),
+			Subnet:         s(m["subnet"]),
 		})
+		expandLinkOptions(m, &out[len(out)-1])
+	}
+	return out
+}
+
+func expandLans(v interface{}) []model.Link {
+	out := expandLinks("lan", v)
+	for i, it := range toList(v) {
+		m := it.(map[string]interface{})
+		out[i].SharedVlanCreate = s(m["shared_vlan_create"])
+		out[i].SharedVlanConnect = s(m["shared_vlan_connect"])
 	}
 	return out
 }
@@ -78,28 +178,61 @@ func expandBridged(v interface{}) []model.Link {
 			Kind:       "bridged_link",
 			Name:       s(m["name"]),
 			Interfaces: expandIfaces(m["interface"]),
-			Bandwidth:  pInt(m, "bandwidth_mbps"),
-			Latency:    pInt(m, "latency_ms"),
-			Plr:        pFloat(m, "plr"),
+			Bandwidth:  pNonZeroInt(m, "bandwidth_mbps"),
+			Latency:    pNonZeroInt(m, "latency_ms"),
+			Plr:        pNonZeroFloat(m, "plr"),
 		}
+		expandLinkOptions(m, &l)
 		out = append(out, l)
 	}
 	return out
 }
 
+func expandLinkOptions(m map[string]interface{}, l *model.Link) {
+	l.VlanTagging = pTrue(m, "vlan_tagging")
+	l.LinkMultiplexing = pTrue(m, "link_multiplexing")
+	l.BestEffort = pTrue(m, "best_effort")
+	l.TrivialOK = pTrue(m, "trivial_ok")
+	l.Protocol = s(m["protocol"])
+	l.Stitching = s(m["stitching"])
+}
+
 func expandIfaces(v interface{}) []model.Iface {
 	var out []model.Iface
 	for _, it := range toList(v) {
 		m := it.(map[string]interface{})
 		out = append(out, model.Iface{
-			Node:   s(m["node"]),
-			IfName: s(m["ifname"]),
+			Node:      s(m["node"]),
+			IfName:    s(m["ifname"]),
+			IP:        s(m["ip"]),
+			Netmask:   s(m["netmask"]),
+			Bandwidth: pNonZeroInt(m, "bandwidth_mbps"),
+			Latency:   pNonZeroInt(m, "latency_ms"),
+			Plr:       pNonZeroFloat(m, "plr"),
 		})
 	}
 	return out
 }
 
 // small helpers
+func toStringMap(v interface{}) map[string]string {
+	m, _ := v.(map[string]interface{})
+	if len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, val := range m {
+		out[k] = s(val)
+	}
+	return out
+}
+func toStrings(v interface{}) []string {
+	var out []string
+	for _, it := range toList(v) {
+		out = append(out, s(it))
+	}
+	return out
+}
 func toList(v interface{}) []interface{} { if v == nil { return nil }; return v.([]interface{}) }
 func s(v interface{}) string             { if v == nil { return "" }; return v.(string) }
 func pInt(m map[string]interface{}, k string) *int {
@@ -123,3 +256,29 @@ func pFloat(m map[string]interface{}, k string) *float64 {
 	}
 	return nil
 }
+
+// pTrue is pBool for optional flags whose unset value reads back as false.
+func pTrue(m map[string]interface{}, k string) *bool {
+	if p := pBool(m, k); p != nil && *p {
+		return p
+	}
+	return nil
+}
+
+// pNonZeroInt is pInt for nested optional attributes, which read back as
+// zero when unset. Only zero means unset; a negative value is kept so
+// validation can reject it.
+func pNonZeroInt(m map[string]interface{}, k string) *int {
+	if p := pInt(m, k); p != nil && *p != 0 {
+		return p
+	}
+	return nil
+}
+
+// pNonZeroFloat is pNonZeroInt for float attributes.
+func pNonZeroFloat(m map[string]interface{}, k string) *float64 {
+	if p := pFloat(m, k); p != nil && *p != 0 {
+		return p
+	}
+	return nil
+}
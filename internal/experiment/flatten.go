@@ -1,6 +1,8 @@
 package experiment
 
 import (
+	"sort"
+
 	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 )
@@ -18,3 +20,29 @@ func setStatusFields(d *schema.ResourceData, p *portalclient.StatusPayload) {
 	}
 	_ = d.Set("nodes", out)
 }
+
+// setStartupFields records per-node startup service state; exit_code is -1
+// while the services are still running.
+func setStartupFields(d *schema.ResourceData, output string) {
+	st, err := portalclient.ParseStartupStatus(output)
+	if err != nil {
+		return
+	}
+	names := make([]string, 0, len(st))
+	for name := range st {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		code := -1
+		if st[name].ExitCode != nil {
+			code = *st[name].ExitCode
+		}
+		out = append(out, map[string]interface{}{
+			"node": name, "status": st[name].State, "exit_code": code,
+		})
+	}
+	_ = d.Set("startup", out)
+}
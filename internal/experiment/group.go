@@ -0,0 +1,84 @@
+package experiment
+
+import (
+	"fmt"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+// groupMemberName is the stable name of member i. Members are numbered from
+// zero, so changing count only adds or removes nodes at the tail.
+func groupMemberName(prefix string, i int) string {
+	return fmt.Sprintf("%s%d", prefix, i)
+}
+
+// expandNodeGroups appends every node_group member to spec.Nodes. When a
+// group names a lan, its members are attached to the lan block of that name,
+// or to a new lan if none is declared.
+func expandNodeGroups(spec *model.ExperimentSpec, v interface{}) {
+	for _, it := range toList(v) {
+		m := it.(map[string]interface{})
+		kind := s(m["kind"])
+		prefix := s(m["name_prefix"])
+		count, _ := m["count"].(int)
+
+		var members []model.Iface
+		for i := 0; i < count; i++ {
+			n := templateNode(kind, groupMemberName(prefix, i), m)
+			spec.Nodes = append(spec.Nodes, n)
+			members = append(members, model.Iface{Node: n.Name})
+		}
+
+		lan := s(m["lan"])
+		if lan == "" || len(members) == 0 {
+			continue
+		}
+		attachToLan(spec, lan, members)
+	}
+}
+
+// templateNode builds a node of the given kind from a node template map.
+func templateNode(kind, name string, m map[string]interface{}) model.Node {
+	n := model.Node{
+		Kind:                kind,
+		Name:                name,
+		DiskImage:           s(m["disk_image"]),
+		Aggregate:           s(m["aggregate"]),
+		AggregatePreference: toStrings(m["aggregate_preference"]),
+		RoutableIP:          pBool(m, "routable_ip"),
+		Blockstores:         expandBlockstores(m["blockstore"]),
+		Execute:             expandExecute(m["execute"]),
+		Install:             expandInstall(m["install"]),
+	}
+	switch kind {
+	case "rawpc":
+		n.HardwareType = s(m["hardware_type"])
+		n.Exclusive = pBool(m, "exclusive")
+	case "xenvm":
+		n.Cores = pNonZeroInt(m, "cores")
+		n.RamMB = pNonZeroInt(m, "ram_mb")
+		n.DiskGB = pNonZeroInt(m, "disk_gb")
+		n.InstantiateOn = s(m["instantiate_on"])
+	case "docker":
+		n.DockerImage = s(m["image"])
+		n.Dockerfile = s(m["dockerfile"])
+		n.ExecOnStart = s(m["exec_on_start"])
+		n.Exclusive = pBool(m, "exclusive")
+		n.Cores = pNonZeroInt(m, "cores")
+		n.RamMB = pNonZeroInt(m, "ram_mb")
+		n.InstantiateOn = s(m["instantiate_on"])
+		n.RoutableIP = nil
+		n.Blockstores = nil
+	}
+	return n
+}
+
+func attachToLan(spec *model.ExperimentSpec, lan string, members []model.Iface) {
+	for i := range spec.Links {
+		if spec.Links[i].Kind == "lan" && spec.Links[i].Name == lan {
+			spec.Links[i].Interfaces = append(spec.Links[i].Interfaces, members...)
+			return
+		}
+	}
+	spec.Links = append(spec.Links, model.Link{Kind: "lan", Name: lan, Interfaces: members})
+}
@@ -0,0 +1,225 @@
+package experiment
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/validation"
+)
+
+type inventoryHost struct {
+	Name string
+	Host string
+	User string
+	Port int
+}
+
+// inventory is an Ansible inventory: hosts with connection variables and
+// groups of host names.
+type inventory struct {
+	Hosts  []inventoryHost
+	Groups map[string][]string
+}
+
+// sliverKinds maps manifest sliver types to node kinds, for nodes the spec
+// does not know (e.g. after import).
+var sliverKinds = map[string]string{"raw-pc": "rawpc", "emulab-xen": "xenvm", "emulab-docker": "docker"}
+
+// buildInventory lists every node the manifests or status report, with its
+// ssh endpoint from the manifest logins (or its status IPv4), grouped by
+// kind, hardware type and aggregate. The spec fills in what the portal
+// leaves out and adds the link/lan groups. Nodes without a known address
+// are left out. With user set, that member's login is used (or user on
+// another member's endpoint); otherwise the first one the manifest lists.
+func buildInventory(spec model.ExperimentSpec, ips map[string]string, manifest []portalclient.ManifestNode, user string) inventory {
+	byName := map[string]portalclient.ManifestNode{}
+	for _, n := range manifest {
+		if n.ClientID != "" {
+			byName[n.ClientID] = n
+		}
+	}
+	specNodes := map[string]model.Node{}
+	for _, n := range spec.Nodes {
+		specNodes[n.Name] = n
+	}
+	names := make([]string, 0, len(byName)+len(ips))
+	for name := range byName {
+		names = append(names, name)
+	}
+	for name := range ips {
+		if _, ok := byName[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	inv := inventory{Groups: map[string][]string{}}
+	add := func(group, host string) {
+		if group != "" {
+			g := inventoryName(group)
+			inv.Groups[g] = append(inv.Groups[g], host)
+		}
+	}
+	included := map[string]bool{}
+	for _, name := range names {
+		m, n := byName[name], specNodes[name]
+		h := inventoryHost{Name: name, Host: ips[name], User: user, Port: 22}
+		if l, ok := pickLogin(m.Logins, user); ok {
+			h.Host = l.Hostname
+			if h.User == "" {
+				h.User = l.Username
+			}
+			if l.Port > 0 {
+				h.Port = l.Port
+			}
+		}
+		if h.Host == "" {
+			continue
+		}
+		inv.Hosts = append(inv.Hosts, h)
+		included[name] = true
+
+		kind := n.Kind
+		if kind == "" {
+			kind = sliverKinds[m.SliverType.Name]
+		}
+		if kind != "" {
+			add("kind_"+kind, name)
+		}
+		hw := m.HardwareType.Name
+		if hw == "" {
+			hw = n.HardwareType
+		}
+		if hw != "" {
+			add("hw_"+hw, name)
+		}
+		agg := m.Aggregate
+		if agg == "" {
+			agg = n.Aggregate
+		}
+		if agg != "" {
+			if short, ok := validation.Aggregates[agg]; ok {
+				agg = short
+			}
+			add("aggregate_"+agg, name)
+		}
+	}
+	for _, l := range spec.Links {
+		for _, ifc := range l.Interfaces {
+			if included[ifc.Node] {
+				add(l.Kind+"_"+l.Name, ifc.Node)
+			}
+		}
+	}
+
+	for g, hosts := range inv.Groups {
+		sort.Strings(hosts)
+		inv.Groups[g] = dedupSorted(hosts)
+	}
+	return inv
+}
+
+// pickLogin prefers user's login; any login gives the ssh endpoint, which
+// is the same for every member.
+func pickLogin(logins []portalclient.Login, user string) (portalclient.Login, bool) {
+	var first *portalclient.Login
+	for i, l := range logins {
+		if l.Hostname == "" {
+			continue
+		}
+		if l.Username == user {
+			return l, true
+		}
+		if first == nil {
+			first = &logins[i]
+		}
+	}
+	if first == nil {
+		return portalclient.Login{}, false
+	}
+	return *first, true
+}
+
+func dedupSorted(in []string) []string {
+	out := in[:0]
+	for i, v := range in {
+		if i == 0 || v != in[i-1] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// inventoryName makes s a valid Ansible group name: letters, digits and
+// underscores.
+func inventoryName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || ('0' <= r && r <= '9') {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+func (inv inventory) groupNames() []string {
+	out := make([]string, 0, len(inv.Groups))
+	for g := range inv.Groups {
+		out = append(out, g)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// INI renders the inventory in Ansible's INI format.
+func (inv inventory) INI() string {
+	var b strings.Builder
+	b.WriteString("[all]\n")
+	for _, h := range inv.Hosts {
+		fmt.Fprintf(&b, "%s ansible_host=%s ansible_port=%d", h.Name, h.Host, h.Port)
+		if h.User != "" {
+			fmt.Fprintf(&b, " ansible_user=%s", h.User)
+		}
+		b.WriteString("\n")
+	}
+	for _, g := range inv.groupNames() {
+		fmt.Fprintf(&b, "\n[%s]\n", g)
+		for _, h := range inv.Groups[g] {
+			b.WriteString(h + "\n")
+		}
+	}
+	return b.String()
+}
+
+// YAML renders the inventory in Ansible's YAML format.
+func (inv inventory) YAML() string {
+	var b strings.Builder
+	b.WriteString("all:\n")
+	if len(inv.Hosts) == 0 {
+		b.WriteString("  hosts: {}\n")
+	} else {
+		b.WriteString("  hosts:\n")
+	}
+	for _, h := range inv.Hosts {
+		fmt.Fprintf(&b, "    %s:\n", strconv.Quote(h.Name))
+		fmt.Fprintf(&b, "      ansible_host: %s\n", strconv.Quote(h.Host))
+		fmt.Fprintf(&b, "      ansible_port: %d\n", h.Port)
+		if h.User != "" {
+			fmt.Fprintf(&b, "      ansible_user: %s\n", strconv.Quote(h.User))
+		}
+	}
+	if len(inv.Groups) == 0 {
+		return b.String()
+	}
+	b.WriteString("  children:\n")
+	for _, g := range inv.groupNames() {
+		fmt.Fprintf(&b, "    %s:\n      hosts:\n", g)
+		for _, h := range inv.Groups[g] {
+			fmt.Fprintf(&b, "        %s: {}\n", strconv.Quote(h))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,144 @@
+package experiment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+const inventoryManifest = `{"urn:publicid:IDN+utah.cloudlab.us+authority+cm": "<rspec><node client_id=\"n0\" component_id=\"urn:publicid:IDN+utah.cloudlab.us+node+pc1\"><sliver_type name=\"raw-pc\"/><hardware_type name=\"m510\"/><services><login authentication=\"ssh-keys\" hostname=\"pc1.utah.cloudlab.us\" port=\"22\" username=\"alice\"/><login authentication=\"ssh-keys\" hostname=\"pc1.utah.cloudlab.us\" port=\"22\" username=\"bob\"/></services></node><node client_id=\"vm1\" component_id=\"urn:publicid:IDN+utah.cloudlab.us+node+pcvm2-1\"><sliver_type name=\"emulab-xen\"/><hardware_type name=\"m510\"/><services><login authentication=\"ssh-keys\" hostname=\"pc2.utah.cloudlab.us\" port=\"26010\" username=\"alice\"/></services></node></rspec>"}`
+
+func testManifest(t *testing.T) []portalclient.ManifestNode {
+	t.Helper()
+	m, err := portalclient.ParseManifestNodes(inventoryManifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+// After import there is no spec; hosts and most groups still come from
+// the manifest.
+func TestBuildInventoryWithoutSpec(t *testing.T) {
+	inv := buildInventory(model.ExperimentSpec{}, map[string]string{"n0": "128.110.1.1"}, testManifest(t), "")
+	wantHosts := []inventoryHost{
+		{Name: "n0", Host: "pc1.utah.cloudlab.us", User: "alice", Port: 22},
+		{Name: "vm1", Host: "pc2.utah.cloudlab.us", User: "alice", Port: 26010},
+	}
+	if !reflect.DeepEqual(inv.Hosts, wantHosts) {
+		t.Fatalf("hosts = %+v", inv.Hosts)
+	}
+	wantGroups := map[string][]string{
+		"kind_rawpc":                 {"n0"},
+		"kind_xenvm":                 {"vm1"},
+		"hw_m510":                    {"n0", "vm1"},
+		"aggregate_utah_cloudlab_us": {"n0", "vm1"},
+	}
+	if !reflect.DeepEqual(inv.Groups, wantGroups) {
+		t.Fatalf("groups = %v", inv.Groups)
+	}
+}
+
+func TestBuildInventoryWithSpec(t *testing.T) {
+	spec := model.ExperimentSpec{
+		Nodes: []model.Node{
+			{Kind: "rawpc", Name: "n0"},
+			{Kind: "xenvm", Name: "vm1"},
+			{Kind: "docker", Name: "c0", Aggregate: "urn:publicid:IDN+clemson.cloudlab.us+authority+cm"},
+			{Kind: "rawpc", Name: "pending"},
+		},
+		Links: []model.Link{
+			{Kind: "lan", Name: "lan-0", Interfaces: []model.Iface{{Node: "n0"}, {Node: "vm1"}, {Node: "c0"}, {Node: "pending"}}},
+			{Kind: "link", Name: "l1", Interfaces: []model.Iface{{Node: "n0"}, {Node: "vm1"}}},
+		},
+	}
+	// c0 is only in the status payload; "pending" has no address yet.
+	inv := buildInventory(spec, map[string]string{"c0": "10.10.1.3"}, testManifest(t), "bob")
+
+	var names []string
+	for _, h := range inv.Hosts {
+		names = append(names, h.Name)
+	}
+	if !reflect.DeepEqual(names, []string{"c0", "n0", "vm1"}) {
+		t.Fatalf("hosts = %v", names)
+	}
+	if h := inv.Hosts[0]; h.Host != "10.10.1.3" || h.User != "bob" || h.Port != 22 {
+		t.Fatalf("c0 = %+v", h)
+	}
+	if h := inv.Hosts[1]; h.Host != "pc1.utah.cloudlab.us" || h.User != "bob" {
+		t.Fatalf("n0 = %+v", h)
+	}
+	// vm1 lists no login for bob; bob still uses its ssh endpoint.
+	if h := inv.Hosts[2]; h.Host != "pc2.utah.cloudlab.us" || h.User != "bob" || h.Port != 26010 {
+		t.Fatalf("vm1 = %+v", h)
+	}
+	for group, want := range map[string][]string{
+		"lan_lan_0":                     {"c0", "n0", "vm1"},
+		"link_l1":                       {"n0", "vm1"},
+		"kind_docker":                   {"c0"},
+		"aggregate_clemson_cloudlab_us": {"c0"},
+	} {
+		if got := inv.Groups[group]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s = %v, want %v", group, got, want)
+		}
+	}
+}
+
+func TestInventoryRendering(t *testing.T) {
+	inv := inventory{
+		Hosts: []inventoryHost{
+			{Name: "n0", Host: "pc1.utah.cloudlab.us", User: "alice", Port: 22},
+			{Name: "vm1", Host: "10.0.0.2", Port: 22},
+		},
+		Groups: map[string][]string{"kind_rawpc": {"n0"}, "lan_lan0": {"n0", "vm1"}},
+	}
+	wantINI := `[all]
+n0 ansible_host=pc1.utah.cloudlab.us ansible_port=22 ansible_user=alice
+vm1 ansible_host=10.0.0.2 ansible_port=22
+
+[kind_rawpc]
+n0
+
+[lan_lan0]
+n0
+vm1
+`
+	if got := inv.INI(); got != wantINI {
+		t.Errorf("INI:\n%s\nwant:\n%s", got, wantINI)
+	}
+	wantYAML := `all:
+  hosts:
+    "n0":
+      ansible_host: "pc1.utah.cloudlab.us"
+      ansible_port: 22
+      ansible_user: "alice"
+    "vm1":
+      ansible_host: "10.0.0.2"
+      ansible_port: 22
+  children:
+    kind_rawpc:
+      hosts:
+        "n0": {}
+    lan_lan0:
+      hosts:
+        "n0": {}
+        "vm1": {}
+`
+	if got := inv.YAML(); got != wantYAML {
+		t.Errorf("YAML:\n%s\nwant:\n%s", got, wantYAML)
+	}
+	if got := (inventory{}).YAML(); got != "all:\n  hosts: {}\n" {
+		t.Errorf("empty YAML = %q", got)
+	}
+}
+
+func TestInventoryName(t *testing.T) {
+	if got := inventoryName("aggregate_utah.cloudlab.us"); got != "aggregate_utah_cloudlab_us" {
+		t.Fatal(got)
+	}
+	if got := inventoryName("lan_my-lan"); got != "lan_my_lan" {
+		t.Fatal(got)
+	}
+}
@@ -0,0 +1,39 @@
+package experiment
+
+import (
+	"fmt"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+func isSet(b *bool) bool { return b != nil && *b }
+
+// validateLinkOptions rejects option combinations CloudLab refuses to map.
+// kinds is node name -> kind.
+func validateLinkOptions(l model.Link, kinds map[string]string) error {
+	if isSet(l.LinkMultiplexing) && !isSet(l.VlanTagging) {
+		return fmt.Errorf("%s %q link_multiplexing requires vlan_tagging", l.Kind, l.Name)
+	}
+	if isSet(l.TrivialOK) {
+		if l.Kind == "bridged_link" {
+			return fmt.Errorf("bridged_link %q cannot be trivial_ok (it always has a bridge node)", l.Name)
+		}
+		if needsDelayNode(l, kinds) {
+			return fmt.Errorf("%s %q cannot be trivial_ok while its shaping needs a delay node (set endnode_shaping)", l.Kind, l.Name)
+		}
+	}
+	if l.Protocol == "infiniband" {
+		if l.Kind == "bridged_link" || linkShaped(l) {
+			return fmt.Errorf("%s %q: infiniband links cannot be shaped", l.Kind, l.Name)
+		}
+		if isSet(l.VlanTagging) || isSet(l.LinkMultiplexing) || isSet(l.TrivialOK) {
+			return fmt.Errorf("%s %q: vlan_tagging, link_multiplexing and trivial_ok are ethernet-only", l.Kind, l.Name)
+		}
+		for _, ifc := range l.Interfaces {
+			if kinds[ifc.Node] != "rawpc" {
+				return fmt.Errorf("%s %q: infiniband endpoint %q must be a rawpc", l.Kind, l.Name, ifc.Node)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package experiment
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+// modifiable reports whether a change to a rawpc, xenvm, link or lan list
+// can be done with portal.modifyExperiment: entries may be added or
+// removed, kept nodes (matched by name) may only change inPlaceNodeKeys
+// and not clear disk_image, kept links and lans may only gain or lose
+// interfaces, and shared VLANs can't come or go.
+func modifiable(key string, old, new []interface{}) bool {
+	byName := map[string]map[string]interface{}{}
+	for _, v := range old {
+		m, _ := v.(map[string]interface{})
+		byName[s(m["name"])] = m
+	}
+	kept := map[string]bool{}
+	for _, v := range new {
+		m, _ := v.(map[string]interface{})
+		name := s(m["name"])
+		o, ok := byName[name]
+		if !ok {
+			if hasSharedVlan(m) {
+				return false
+			}
+			continue
+		}
+		kept[name] = true
+		if key == "rawpc" || key == "xenvm" {
+			if !reflect.DeepEqual(withoutInPlace(o), withoutInPlace(m)) {
+				return false
+			}
+			// A reload without an image keeps the current one, so going
+			// back to the cluster default needs a new experiment.
+			if s(o["disk_image"]) != "" && s(m["disk_image"]) == "" {
+				return false
+			}
+		} else if !sameExceptMembers(o, m) {
+			return false
+		}
+	}
+	for name, m := range byName {
+		if !kept[name] && hasSharedVlan(m) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameExceptMembers compares two link or lan blocks ignoring which
+// interfaces come or go; an interface on the same node in both must be
+// unchanged.
+func sameExceptMembers(old, new map[string]interface{}) bool {
+	strip := func(m map[string]interface{}) map[string]interface{} {
+		out := map[string]interface{}{}
+		for k, v := range m {
+			if k != "interface" {
+				out[k] = v
+			}
+		}
+		return out
+	}
+	if !reflect.DeepEqual(strip(old), strip(new)) {
+		return false
+	}
+	byNode := map[string]interface{}{}
+	for _, v := range toList(old["interface"]) {
+		m, _ := v.(map[string]interface{})
+		byNode[s(m["node"])] = v
+	}
+	for _, v := range toList(new["interface"]) {
+		m, _ := v.(map[string]interface{})
+		if o, ok := byNode[s(m["node"])]; ok && !reflect.DeepEqual(o, v) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasSharedVlan(m map[string]interface{}) bool {
+	return s(m["shared_vlan_create"]) != "" || s(m["shared_vlan_connect"]) != ""
+}
+
+// topologyChanged reports whether nodes, links or link members were added
+// or removed.
+func topologyChanged(oldSpec, newSpec model.ExperimentSpec) bool {
+	return !reflect.DeepEqual(nodeNames(oldSpec), nodeNames(newSpec)) ||
+		!reflect.DeepEqual(linkMembers(oldSpec), linkMembers(newSpec))
+}
+
+// linkMembers maps "kind/name" of each link to its sorted member nodes.
+func linkMembers(spec model.ExperimentSpec) map[string][]string {
+	out := map[string][]string{}
+	for _, l := range spec.Links {
+		nodes := []string{}
+		for _, ifc := range l.Interfaces {
+			nodes = append(nodes, ifc.Node)
+		}
+		sort.Strings(nodes)
+		out[l.Kind+"/"+l.Name] = nodes
+	}
+	return out
+}
+
+func nodeNames(spec model.ExperimentSpec) []string {
+	out := []string{}
+	for _, n := range spec.Nodes {
+		out = append(out, n.Name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func linkNames(spec model.ExperimentSpec) []string {
+	out := []string{}
+	for _, l := range spec.Links {
+		out = append(out, l.Kind+"/"+l.Name)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// touchedNodes lists the nodes of newSpec that a modification adds, or
+// gives or takes an interface.
+func touchedNodes(oldSpec, newSpec model.ExperimentSpec) []string {
+	links := func(spec model.ExperimentSpec) map[string][]string {
+		out := map[string][]string{}
+		for _, n := range spec.Nodes {
+			out[n.Name] = []string{}
+		}
+		for _, l := range spec.Links {
+			for _, ifc := range l.Interfaces {
+				out[ifc.Node] = append(out[ifc.Node], l.Kind+"/"+l.Name)
+			}
+		}
+		for _, v := range out {
+			sort.Strings(v)
+		}
+		return out
+	}
+	before, after := links(oldSpec), links(newSpec)
+	var out []string
+	for _, n := range newSpec.Nodes {
+		if b, ok := before[n.Name]; !ok || !reflect.DeepEqual(b, after[n.Name]) {
+			out = append(out, n.Name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// modifyTopology submits spec as a modification of the running experiment
+// and waits until the added and rewired nodes (touchedNodes) are back at
+// waitFor. Nodes already placed keep their aggregate (from node_aggregates)
+// and interfaces keep their allocated address (from addresses); new nodes
+// take their first preference. It returns the spec that was placed.
+func modifyTopology(ctx context.Context, d *schema.ResourceData, cfg *portalclient.Config, project, expName string, oldSpec, spec model.ExperimentSpec, waitFor string) (model.ExperimentSpec, error) {
+	current, _ := d.Get("node_aggregates").(map[string]interface{})
+	pinned := spec
+	pinned.Nodes = make([]model.Node, len(spec.Nodes))
+	for i, n := range spec.Nodes {
+		if agg := s(current[n.Name]); n.Aggregate == "" && agg != "" {
+			n.Aggregate = agg
+			n.AggregatePreference = nil
+		}
+		pinned.Nodes[i] = n
+	}
+
+	// Removing a member must not shift the addresses allocated after it.
+	addrs, _ := d.Get("addresses").(map[string]interface{})
+	pinned.Links = make([]model.Link, len(spec.Links))
+	for i, l := range spec.Links {
+		l.Interfaces = append([]model.Iface(nil), l.Interfaces...)
+		for k, ifc := range l.Interfaces {
+			if a := s(addrs[l.Name+"/"+ifc.Node]); ifc.IP == "" && a != "" {
+				l.Interfaces[k].IP = a
+			}
+		}
+		pinned.Links[i] = l
+	}
+
+	placed := placeAttempt(pinned, toStrings(d.Get("aggregate_preference")), 0)
+	if err := validateSpec(placed); err != nil {
+		return placed, err
+	}
+	if err := assignAddresses(&placed); err != nil {
+		return placed, err
+	}
+	specJSON, err := encodeSpec(placed)
+	if err != nil {
+		return placed, err
+	}
+
+	tflog.Info(ctx, "modifying experiment", map[string]any{
+		"experiment": expName, "nodes": nodeNames(placed), "links": linkNames(placed),
+	})
+	if _, err := portalclient.Modify(cfg.Client, project, expName, encodeBindings(specJSON)); err != nil {
+		return placed, err
+	}
+	touched := touchedNodes(oldSpec, placed)
+	if len(touched) == 0 {
+		return placed, nil // only removals; nothing comes up
+	}
+	return placed, waitForNodes(ctx, cfg, project, expName, placed, touched, waitFor)
+}
@@ -0,0 +1,126 @@
+package experiment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+func node(name, image string) map[string]interface{} {
+	return map[string]interface{}{"name": name, "hardware_type": "d430", "disk_image": image}
+}
+
+func lan(name string, nodes ...string) map[string]interface{} {
+	var ifaces []interface{}
+	for _, n := range nodes {
+		ifaces = append(ifaces, map[string]interface{}{"node": n, "ip": ""})
+	}
+	return map[string]interface{}{"name": name, "bandwidth_mbps": 0, "interface": ifaces, "shared_vlan_create": "", "shared_vlan_connect": ""}
+}
+
+func TestModifiable(t *testing.T) {
+	changedHW := node("a", "img")
+	changedHW["hardware_type"] = "m510"
+	shaped := lan("lan0", "a", "b")
+	shaped["bandwidth_mbps"] = 100
+	addressed := lan("lan0", "a", "b")
+	addressed["interface"].([]interface{})[0].(map[string]interface{})["ip"] = "10.0.0.9"
+	shared := lan("vlan", "a")
+	shared["shared_vlan_create"] = "my-vlan"
+
+	tests := []struct {
+		name     string
+		key      string
+		old, new []interface{}
+		want     bool
+	}{
+		{"add node", "rawpc", []interface{}{node("a", "img")}, []interface{}{node("a", "img"), node("b", "img")}, true},
+		{"remove node", "rawpc", []interface{}{node("a", "img"), node("b", "img")}, []interface{}{node("b", "img")}, true},
+		{"reorder nodes", "rawpc", []interface{}{node("a", "img"), node("b", "img")}, []interface{}{node("b", "img"), node("a", "img")}, true},
+		{"change image", "rawpc", []interface{}{node("a", "img")}, []interface{}{node("a", "img2")}, true},
+		{"clear image", "rawpc", []interface{}{node("a", "img")}, []interface{}{node("a", "")}, false},
+		{"set image", "rawpc", []interface{}{node("a", "")}, []interface{}{node("a", "img")}, true},
+		{"change hardware", "rawpc", []interface{}{node("a", "img")}, []interface{}{changedHW}, false},
+		{"add lan", "lan", []interface{}{lan("lan0", "a", "b")}, []interface{}{lan("lan0", "a", "b"), lan("lan1", "b", "c")}, true},
+		{"grow lan", "lan", []interface{}{lan("lan0", "a", "b")}, []interface{}{lan("lan0", "a", "b", "c")}, true},
+		{"shrink lan", "lan", []interface{}{lan("lan0", "a", "b", "c")}, []interface{}{lan("lan0", "a", "c")}, true},
+		{"reshape lan", "lan", []interface{}{lan("lan0", "a", "b")}, []interface{}{shaped}, false},
+		{"readdress member", "lan", []interface{}{lan("lan0", "a", "b")}, []interface{}{addressed}, false},
+		{"add shared vlan", "lan", nil, []interface{}{shared}, false},
+		{"remove shared vlan", "lan", []interface{}{shared}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := modifiable(tt.key, tt.old, tt.new); got != tt.want {
+				t.Fatalf("modifiable = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopologyChanged(t *testing.T) {
+	base := model.ExperimentSpec{
+		Nodes: []model.Node{{Name: "a"}, {Name: "b"}},
+		Links: []model.Link{{Kind: "lan", Name: "lan0", Interfaces: []model.Iface{{Node: "a"}, {Node: "b"}}}},
+	}
+	reordered := model.ExperimentSpec{
+		Nodes: []model.Node{{Name: "b"}, {Name: "a", DiskImage: "new"}},
+		Links: base.Links,
+	}
+	grown := model.ExperimentSpec{
+		Nodes: []model.Node{{Name: "a"}, {Name: "b"}, {Name: "c"}},
+		Links: []model.Link{{Kind: "lan", Name: "lan0", Interfaces: []model.Iface{{Node: "a"}, {Node: "b"}, {Node: "c"}}}},
+	}
+	if topologyChanged(base, reordered) {
+		t.Error("reorder and image change reported as topology change")
+	}
+	if !topologyChanged(base, grown) {
+		t.Error("added node and lan member not reported")
+	}
+	member := model.ExperimentSpec{
+		Nodes: base.Nodes,
+		Links: []model.Link{{Kind: "lan", Name: "lan0", Interfaces: []model.Iface{{Node: "a"}}}},
+	}
+	if !topologyChanged(base, member) {
+		t.Error("removed lan member not reported")
+	}
+}
+
+func TestTouchedNodes(t *testing.T) {
+	nodes := func(names ...string) []model.Node {
+		var out []model.Node
+		for _, n := range names {
+			out = append(out, model.Node{Name: n})
+		}
+		return out
+	}
+	link := func(kind, name string, members ...string) model.Link {
+		l := model.Link{Kind: kind, Name: name}
+		for _, m := range members {
+			l.Interfaces = append(l.Interfaces, model.Iface{Node: m})
+		}
+		return l
+	}
+	base := model.ExperimentSpec{Nodes: nodes("a", "b", "c"), Links: []model.Link{link("lan", "lan0", "a", "b")}}
+	tests := []struct {
+		name string
+		new  model.ExperimentSpec
+		want []string
+	}{
+		{"unchanged", base, nil},
+		{"add isolated node", model.ExperimentSpec{Nodes: nodes("a", "b", "c", "d"), Links: base.Links}, []string{"d"}},
+		{"add node to lan", model.ExperimentSpec{Nodes: nodes("a", "b", "c", "d"), Links: []model.Link{link("lan", "lan0", "a", "b", "d")}}, []string{"d"}},
+		{"join existing node", model.ExperimentSpec{Nodes: base.Nodes, Links: []model.Link{link("lan", "lan0", "a", "b", "c")}}, []string{"c"}},
+		{"leave lan", model.ExperimentSpec{Nodes: base.Nodes, Links: []model.Link{link("lan", "lan0", "a", "c")}}, []string{"b", "c"}},
+		{"remove node", model.ExperimentSpec{Nodes: nodes("a", "b"), Links: base.Links}, nil},
+		{"new link", model.ExperimentSpec{Nodes: base.Nodes, Links: append([]model.Link{link("link", "l1", "b", "c")}, base.Links...)}, []string{"b", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := touchedNodes(base, tt.new); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("touchedNodes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
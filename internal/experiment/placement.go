@@ -0,0 +1,77 @@
+package experiment
+
+import (
+	"fmt"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/validation"
+)
+
+// placementAttempts is how many sites resourceCreate may try: the length of
+// the longest aggregate_preference list, or 1 without any.
+func placementAttempts(spec model.ExperimentSpec, expPref []string) int {
+	n := len(expPref)
+	for _, node := range spec.Nodes {
+		if len(node.AggregatePreference) > n {
+			n = len(node.AggregatePreference)
+		}
+	}
+	if n == 0 {
+		return 1
+	}
+	return n
+}
+
+// placeAttempt returns a copy of spec with every node's aggregate resolved
+// for attempt i. A node's own preference list wins over the experiment's;
+// a list shorter than the attempt count keeps its last entry.
+func placeAttempt(spec model.ExperimentSpec, expPref []string, i int) model.ExperimentSpec {
+	out := spec
+	out.Nodes = make([]model.Node, len(spec.Nodes))
+	for k, n := range spec.Nodes {
+		if n.Aggregate == "" {
+			pref := n.AggregatePreference
+			if len(pref) == 0 {
+				pref = expPref
+			}
+			if len(pref) > 0 {
+				n.Aggregate = pref[min(i, len(pref)-1)]
+			}
+		}
+		n.AggregatePreference = nil
+		out.Nodes[k] = n
+	}
+	return out
+}
+
+func validatePreferences(spec model.ExperimentSpec, expPref []string) error {
+	for _, a := range expPref {
+		if !validation.IsValidAggregate(a) || a == "" {
+			return fmt.Errorf("aggregate_preference %q is not a recognized URN", a)
+		}
+	}
+	for _, n := range spec.Nodes {
+		if len(n.AggregatePreference) == 0 {
+			continue
+		}
+		if n.Aggregate != "" {
+			return fmt.Errorf("node %q sets both aggregate and aggregate_preference", n.Name)
+		}
+		for _, a := range n.AggregatePreference {
+			if !validation.IsValidAggregate(a) || a == "" {
+				return fmt.Errorf("node %q aggregate_preference %q is not a recognized URN", n.Name, a)
+			}
+		}
+	}
+	return nil
+}
+
+func flattenNodeAggregates(spec model.ExperimentSpec) map[string]string {
+	out := map[string]string{}
+	for _, n := range spec.Nodes {
+		if n.Aggregate != "" {
+			out[n.Name] = n.Aggregate
+		}
+	}
+	return out
+}
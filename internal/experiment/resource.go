@@ -2,6 +2,7 @@ package experiment
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -9,46 +10,178 @@ import (
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
 	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
 )
 
-// Starts the experiment and waits until the requested status.
+// Starts the experiment and waits until the requested status. With an
+// aggregate_preference, a start refused for lack of resources is torn down
+// and retried on the next preferred site; with availability_retry, the whole
+// round is resubmitted until the hardware frees up.
 func resourceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	cfg := meta.(*portalclient.Config)
 
-	spec := buildSpec(d)
-	if err := validateSpec(spec); err != nil { return diag.FromErr(err) }
-	specJSON, err := encodeSpec(spec)
+	spec, err := buildSpec(d)
 	if err != nil { return diag.FromErr(err) }
+	expPref := toStrings(d.Get("aggregate_preference"))
+	if err := validatePreferences(spec, expPref); err != nil { return diag.FromErr(err) }
+	if err := validateSpec(spec); err != nil { return diag.FromErr(err) }
 
 	expName := d.Get("name").(string)
 	project := d.Get("project").(string)
 	if project == "" { project = cfg.Project }
+	waitFor := canon(d.Get("wait_for_status").(string))
+	opts, err := expandStartOptions(d, time.Now())
+	if err != nil { return diag.FromErr(err) }
+	if err := validateScheduledSpec(spec, opts); err != nil { return diag.FromErr(err) }
+
+	// Timeouts cover every attempt.
+	to := d.Timeout(schema.TimeoutCreate)
+	if to == 0 { to = 30 * time.Minute }
+	ctxCreate, cancel := context.WithTimeout(ctx, to)
+	defer cancel()
+
+	retryFor, retryEvery, err := availabilityRetry(d)
+	if err != nil { return diag.FromErr(err) }
+	retryUntil := time.Now().Add(retryFor)
+	if dl, ok := ctxCreate.Deadline(); ok && dl.Before(retryUntil) {
+		// max_duration is capped by timeouts.create.
+		retryUntil = dl
+	}
+
+	var placed model.ExperimentSpec
+	for round := 1; ; round++ {
+		placed, err = createOnce(ctxCreate, cfg, project, expName, spec, expPref, waitFor, opts)
+		if err == nil {
+			break
+		}
+		if !portalclient.IsInsufficientResources(err) || retryFor == 0 {
+			return diag.FromErr(err)
+		}
+		if time.Now().Add(retryEvery).After(retryUntil) {
+			return diag.Errorf("gave up waiting for hardware for %q after %d attempts (availability_retry max_duration %s, capped by timeouts.create): %v", expName, round, retryFor, err)
+		}
+		tflog.Info(ctx, "hardware unavailable; will resubmit", map[string]any{
+			"experiment": expName, "attempt": round, "retry_in": retryEvery.String(),
+			"retry_until": retryUntil.Format(time.RFC3339), "error": err,
+		})
+		if err := terminateAndWait(ctxCreate, cfg, project, expName); err != nil {
+			return diag.FromErr(err)
+		}
+		select {
+		case <-ctxCreate.Done():
+			return diag.Errorf("gave up waiting for hardware for %q after %d attempts: %v", expName, round, ctxCreate.Err())
+		case <-time.After(retryEvery):
+		}
+	}
+
+	d.SetId(expName)
+	if err := applyPowerStates(ctx, cfg, project, expName, model.ExperimentSpec{}, placed); err != nil {
+		return diag.FromErr(err)
+	}
+	_ = d.Set("addresses", flattenAddresses(placed))
+	_ = d.Set("shared_vlans", flattenSharedVlans(placed))
+	_ = d.Set("stitched_links", flattenStitchedLinks(placed))
+	_ = d.Set("node_aggregates", flattenNodeAggregates(placed))
+	return resourceRead(ctx, d, meta)
+}
+
+// createOnce tries each aggregate_preference site in turn, moving on only
+// when the portal refuses a site for lack of resources. It returns the spec
+// that was placed.
+func createOnce(ctx context.Context, cfg *portalclient.Config, project, expName string, spec model.ExperimentSpec, expPref []string, waitFor string, opts startOptions) (model.ExperimentSpec, error) {
+	attempts := placementAttempts(spec, expPref)
+	for i := 0; ; i++ {
+		placed := placeAttempt(spec, expPref, i)
+		if err := validateSpec(placed); err != nil { return placed, err }
+		if err := assignAddresses(&placed); err != nil { return placed, err }
+		specJSON, err := encodeSpec(placed)
+		if err != nil { return placed, err }
+
+		err = startAndWait(ctx, cfg, project, expName, placed, specJSON, waitFor, opts)
+		if err == nil || i == attempts-1 || !portalclient.IsInsufficientResources(err) {
+			return placed, err
+		}
+		tflog.Warn(ctx, "insufficient resources; trying next aggregate", map[string]any{
+			"experiment": expName, "attempt": i + 1, "error": err,
+		})
+		if err := terminateAndWait(ctx, cfg, project, expName); err != nil {
+			return placed, err
+		}
+	}
+}
+
+// availabilityRetry returns how long and how often to resubmit while the
+// portal reports insufficient resources; zero duration disables retrying.
+func availabilityRetry(d *schema.ResourceData) (time.Duration, time.Duration, error) {
+	l := toList(d.Get("availability_retry"))
+	if len(l) == 0 || l[0] == nil {
+		return 0, 0, nil
+	}
+	m := l[0].(map[string]interface{})
+	maxDur, err := time.ParseDuration(s(m["max_duration"]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("availability_retry max_duration: %w", err)
+	}
+	every, err := time.ParseDuration(s(m["interval"]))
+	if err != nil {
+		return 0, 0, fmt.Errorf("availability_retry interval: %w", err)
+	}
+	return maxDur, every, nil
+}
 
-	params := composeParams(project, expName, specJSON)
+// startAndWait submits one StartExperiment and polls until waitFor, or only
+// until the portal accepts the schedule when start_at is set. ctx carries
+// the create deadline.
+func startAndWait(ctx context.Context, cfg *portalclient.Config, project, expName string, spec model.ExperimentSpec, specJSON, waitFor string, opts startOptions) error {
+	params := composeParams(project, expName, specJSON, opts)
 	tflog.Info(ctx, "starting experiment", map[string]any{"project": project, "experiment": expName})
 	if _, err := portalclient.StartExperiment(cfg.Client, params); err != nil {
-		return diag.FromErr(err)
+		return err
 	}
 
-	waitFor := canon(d.Get("wait_for_status").(string))
+	if !opts.Start.IsZero() {
+		// Nothing happens before the scheduled start, which may be days
+		// away; return once the portal has accepted the schedule.
+		return waitScheduled(ctx, cfg, project, expName)
+	}
+	warmup := 15 * time.Second // allow control plane to register
+	return waitForStatus(ctx, cfg, project, expName, spec, waitFor, warmup)
+}
+
+// waitForStatus polls the experiment until waitFor, starting after delay.
+// ctx carries the deadline.
+func waitForStatus(ctx context.Context, cfg *portalclient.Config, project, expName string, spec model.ExperimentSpec, waitFor string, delay time.Duration) error {
 	pred := Predicate(ctx, waitFor)
 
-	// Timeouts/polling
-	to := d.Timeout(schema.TimeoutCreate)
-	if to == 0 { to = 30 * time.Minute }
+	// Polling
 	poll := 10 * time.Second
-	warmup := 15 * time.Second // allow control plane to register
+	to := 30 * time.Minute
+	if dl, ok := ctx.Deadline(); ok {
+		to = time.Until(dl)
+	}
+
+	pending := []string{
+		StatusScheduled, StatusWaiting, // start_at in the future
+		StatusProvisioning, StatusProvisioned,
+		StatusCreating, StatusCreated,
+		StatusBooting, StatusBooted,
+	}
+	var startupNodes []string
+	if waitFor == StatusStartup {
+		pending = append(pending, StatusReady) // ready, services still running
+		for _, n := range spec.Nodes {
+			if len(n.Execute) > 0 {
+				startupNodes = append(startupNodes, n.Name)
+			}
+		}
+	}
 
 	stateConf := &retry.StateChangeConf{
-		Pending: []string{
-			StatusProvisioning, StatusProvisioned,
-			StatusCreating, StatusCreated,
-			StatusBooting, StatusBooted,
-		},
+		Pending:    pending,
 		Target:     []string{waitFor}, // dynamic: exactly what user asked for
 		Timeout:    to,
-		Delay:      warmup,
+		Delay:      delay,
 		MinTimeout: poll,
 		Refresh: func() (interface{}, string, error) {
 			resp, err := portalclient.Status(cfg.Client, project, expName, true, false, true)
@@ -70,6 +203,24 @@ func resourceCreate(ctx context.Context, d *schema.ResourceData, meta interface{
 				"nodes": len(portalclient.FlattenNodes(p)),
 			})
 
+			if canon(p.Status) == StatusFailed {
+				return p, p.Status, &portalclient.FailedError{Experiment: expName, Output: resp.Output}
+			}
+			if pred(p) && waitFor == StatusStartup {
+				st, serr := portalclient.ParseStartupStatus(resp.Output)
+				if serr != nil {
+					tflog.Warn(ctx, "bad startup status; retrying", map[string]any{"error": serr})
+					return p, p.Status, nil
+				}
+				done, serr := startupDone(st, startupNodes)
+				if serr != nil {
+					return p, p.Status, serr
+				}
+				if !done {
+					return p, p.Status, nil
+				}
+				return p, waitFor, nil
+			}
 			if pred(p) {
 				return p, waitFor, nil // success: return exactly the waited-for state
 			}
@@ -77,21 +228,16 @@ func resourceCreate(ctx context.Context, d *schema.ResourceData, meta interface{
 		},
 	}
 
-	ctxWait, cancel := context.WithTimeout(ctx, to)
-	defer cancel()
-
-	out, err := stateConf.WaitForStateContext(ctxWait)
+	out, err := stateConf.WaitForStateContext(ctx)
 	if err != nil {
 		last := ""
 		if p, ok := out.(*portalclient.StatusPayload); ok && p != nil {
 			last = p.Status
 		}
-		return diag.Errorf("waiting for %q to reach %q failed (last=%q): %v",
+		return fmt.Errorf("waiting for %q to reach %q failed (last=%q): %w",
 			expName, waitFor, last, err)
 	}
-
-	d.SetId(expName)
-	return resourceRead(ctx, d, meta)
+	return nil
 }
 
 // Reads experiment state into Terraform.
@@ -119,9 +265,38 @@ func resourceRead(ctx context.Context, d *schema.ResourceData, meta interface{})
 		return diag.FromErr(err)
 	}
 	setStatusFields(d, p)
+	setStartupFields(d, resp.Output)
+
+	// Manifests are best-effort; they are only used for computed components
+	// and the inventory.
+	var mnodes []portalclient.ManifestNode
+	if mresp, err := portalclient.Manifests(cfg.Client, project, expName); err != nil {
+		tflog.Warn(ctx, "manifest fetch failed", map[string]any{"experiment": expName, "error": err})
+	} else if mnodes, err = portalclient.ParseManifestNodes(mresp.Output); err != nil {
+		tflog.Warn(ctx, "bad manifests", map[string]any{"experiment": expName, "error": err})
+	} else {
+		_ = d.Set("components", portalclient.ManifestComponents(mnodes))
+	}
+	setInventoryFields(ctx, d, p, mnodes)
 	return nil
 }
 
+func setInventoryFields(ctx context.Context, d *schema.ResourceData, p *portalclient.StatusPayload, mnodes []portalclient.ManifestNode) {
+	// The spec only adds groups; hosts come from the portal, so an imported
+	// experiment (no spec yet) still gets an inventory.
+	spec, err := buildSpec(d)
+	if err != nil {
+		tflog.Warn(ctx, "cannot expand spec for inventory groups", map[string]any{"error": err})
+		spec = model.ExperimentSpec{}
+	}
+	ips := map[string]string{}
+	for id, n := range portalclient.FlattenNodes(p) {
+		ips[id] = n.IPv4
+	}
+	inv := buildInventory(spec, ips, mnodes, d.Get("ansible_user").(string))
+	_ = d.Set("ansible_inventory", map[string]string{"ini": inv.INI(), "yaml": inv.YAML()})
+}
+
 // Deletes the experiment.
 func resourceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
 	cfg := meta.(*portalclient.Config)
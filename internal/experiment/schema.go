@@ -1,6 +1,9 @@
 package experiment
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
 )
@@ -9,17 +12,44 @@ func Resource() *schema.Resource {
 	return &schema.Resource{
 		CreateContext: resourceCreate,
 		ReadContext:   resourceRead,
+		UpdateContext: resourceUpdate,
 		DeleteContext: resourceDelete,
+		CustomizeDiff: customizeDiff,
+		Timeouts: &schema.ResourceTimeout{
+			Create: schema.DefaultTimeout(30 * time.Minute),
+			Update: schema.DefaultTimeout(30 * time.Minute),
+		},
 		Schema: map[string]*schema.Schema{
 			"name":     {Type: schema.TypeString, Required: true, ForceNew: true},
 			"project":  {Type: schema.TypeString, Optional: true, ForceNew: true},
 			"pem_path": {Type: schema.TypeString, Optional: true, ForceNew: true},
 
+			// ordered fallback sites for nodes without aggregate/aggregate_preference
+			"aggregate_preference": {Type: schema.TypeList, Optional: true, ForceNew: true, Elem: &schema.Schema{Type: schema.TypeString}},
+
+			// public keys installed for every node's login users
+			"ssh_public_keys": {Type: schema.TypeList, Optional: true, ForceNew: true, Elem: &schema.Schema{Type: schema.TypeString}},
+
+			// any change reboots every node (like null_resource triggers)
+			"reboot_triggers": {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+
+			// cloudlab_reservation id the experiment should start against
+			"reservation_id": {Type: schema.TypeString, Optional: true, ForceNew: true},
+
+			// scheduling (RFC3339); stop_at and duration_hours are exclusive.
+			// With start_at, create returns once the portal has scheduled the
+			// experiment; wait_for_status is not awaited.
+			"start_at":       {Type: schema.TypeString, Optional: true, ForceNew: true, ValidateFunc: validation.IsRFC3339Time},
+			"stop_at":        {Type: schema.TypeString, Optional: true, ForceNew: true, ValidateFunc: validation.IsRFC3339Time, ConflictsWith: []string{"duration_hours"}},
+			"duration_hours": {Type: schema.TypeInt, Optional: true, ForceNew: true, ValidateFunc: validation.IntAtLeast(1)},
+
+			"availability_retry": {Type: schema.TypeList, Optional: true, MaxItems: 1, ForceNew: true, Elem: availabilityRetryBlock()},
+
 			"wait_for_status": {
 				Type:         schema.TypeString,
 				Optional:     true,
 				Default:      "provisioned",
-				ValidateFunc: validation.StringInSlice([]string{"provisioned", "ready"}, false),
+				ValidateFunc: validation.StringInSlice([]string{StatusProvisioned, StatusReady, StatusStartup}, false),
 				ForceNew:     true,
 			},
 
@@ -29,47 +59,196 @@ func Resource() *schema.Resource {
 			"status":  {Type: schema.TypeString, Computed: true},
 			"expires": {Type: schema.TypeString, Computed: true},
 			"nodes":   {Type: schema.TypeMap, Elem: &schema.Schema{Type: schema.TypeString}, Computed: true},
+			"startup": {Type: schema.TypeList, Elem: startupStatusBlock(), Computed: true},
+			// node -> component URN of the physical machine actually allocated
+			"components":     {Type: schema.TypeMap, Elem: &schema.Schema{Type: schema.TypeString}, Computed: true},
+			"stitched_links": {Type: schema.TypeList, Elem: stitchedLinkBlock(), Computed: true},
+			// node -> aggregate the experiment was finally placed on
+			"node_aggregates": {Type: schema.TypeMap, Elem: &schema.Schema{Type: schema.TypeString}, Computed: true},
+			// "ini" and "yaml" Ansible inventories grouped by kind, hardware
+			// type, aggregate and link/LAN
+			"ansible_inventory": {Type: schema.TypeMap, Elem: &schema.Schema{Type: schema.TypeString}, Computed: true},
+			// login to use in ansible_inventory; default is the manifest's first
+			"ansible_user": {Type: schema.TypeString, Optional: true},
+			// "<link>/<node>" -> "10.10.1.1/24" for every addressed interface
+			"addresses": {Type: schema.TypeMap, Elem: &schema.Schema{Type: schema.TypeString}, Computed: true},
+			// lan name -> shared VLAN name created by this experiment
+			"shared_vlans": {Type: schema.TypeMap, Elem: &schema.Schema{Type: schema.TypeString}, Computed: true},
 
-			"rawpc":        {Type: schema.TypeList, Optional: true, Elem: rawpcBlock(), ForceNew: true},
-			"xenvm":        {Type: schema.TypeList, Optional: true, Elem: xenvmBlock(), ForceNew: true},
-			"link":         {Type: schema.TypeList, Optional: true, Elem: linkBlock(), ForceNew: true},
-			"lan":          {Type: schema.TypeList, Optional: true, Elem: lanBlock(), ForceNew: true},
+			// nodes, links and lans can be added or removed, interfaces added to
+			// or removed from a kept link/lan, and disk_image (but not cleared),
+			// power_state and reboot_triggers changed, in place; any other
+			// change to a kept entry replaces the experiment (see customizeDiff)
+			"rawpc":        {Type: schema.TypeList, Optional: true, Elem: rawpcBlock()},
+			"xenvm":        {Type: schema.TypeList, Optional: true, Elem: xenvmBlock()},
+			"docker":       {Type: schema.TypeList, Optional: true, Elem: dockerBlock(), ForceNew: true},
+			"link":         {Type: schema.TypeList, Optional: true, Elem: linkBlock()},
+			"lan":          {Type: schema.TypeList, Optional: true, Elem: lanBlock()},
 			"bridged_link": {Type: schema.TypeList, Optional: true, Elem: bridgedLinkBlock(), ForceNew: true},
+			"node_group":   {Type: schema.TypeList, Optional: true, Elem: nodeGroupBlock(), ForceNew: true},
+			"topology":     {Type: schema.TypeList, Optional: true, Elem: topologyBlock(), ForceNew: true},
 		},
 	}
 }
 
+// availabilityRetryBlock keeps resubmitting while hardware is unavailable.
+// The create timeout still bounds the total wait.
+func availabilityRetryBlock() *schema.Resource {
+	return &schema.Resource{Schema: map[string]*schema.Schema{
+		// Retries run inside the create timeout, so max_duration is capped by
+		// timeouts.create (30m unless raised); keep it below that.
+		"max_duration": {Type: schema.TypeString, Optional: true, Default: "20m", ValidateFunc: validateDuration},
+		"interval":     {Type: schema.TypeString, Optional: true, Default: "5m", ValidateFunc: validateDuration},
+	}}
+}
+
+func validateDuration(v interface{}, k string) ([]string, []error) {
+	if d, err := time.ParseDuration(v.(string)); err != nil || d <= 0 {
+		return nil, []error{fmt.Errorf("%s must be a positive duration like \"30m\" (got %q)", k, v)}
+	}
+	return nil, nil
+}
+
 func rawpcBlock() *schema.Resource {
 	return &schema.Resource{Schema: map[string]*schema.Schema{
-		"name":          {Type: schema.TypeString, Required: true},
-		"hardware_type": {Type: schema.TypeString, Optional: true},
-		"exclusive":     {Type: schema.TypeBool, Optional: true},
-		"disk_image":    {Type: schema.TypeString, Optional: true},
-		"aggregate":     {Type: schema.TypeString, Optional: true}, // optional
-		"routable_ip":   {Type: schema.TypeBool, Optional: true},
-		"blockstore":    {Type: schema.TypeList, Optional: true, Elem: blockstoreBlock()},
+		"name":                 {Type: schema.TypeString, Required: true},
+		"hardware_type":        {Type: schema.TypeString, Optional: true},
+		"component_id":         {Type: schema.TypeString, Optional: true}, // pin to a physical node
+		"exclusive":            {Type: schema.TypeBool, Optional: true},
+		"disk_image":           {Type: schema.TypeString, Optional: true},
+		"power_state":          {Type: schema.TypeString, Optional: true, Default: PowerOn, ValidateFunc: validation.StringInSlice([]string{PowerOn, PowerOff}, false)},
+		"reboot_triggers":      {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		"aggregate":            {Type: schema.TypeString, Optional: true}, // optional
+		"aggregate_preference": {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		"routable_ip":          {Type: schema.TypeBool, Optional: true},
+		"blockstore":           {Type: schema.TypeList, Optional: true, Elem: blockstoreBlock()},
+		"execute":              {Type: schema.TypeList, Optional: true, Elem: executeBlock()},
+		"install":              {Type: schema.TypeList, Optional: true, Elem: installBlock()},
 	}}
 }
 
 func xenvmBlock() *schema.Resource {
 	return &schema.Resource{Schema: map[string]*schema.Schema{
-		"name":           {Type: schema.TypeString, Required: true},
-		"cores":          {Type: schema.TypeInt, Optional: true},
-		"ram_mb":         {Type: schema.TypeInt, Optional: true},
-		"disk_gb":        {Type: schema.TypeInt, Optional: true},
-		"instantiate_on": {Type: schema.TypeString, Optional: true},
-		"disk_image":     {Type: schema.TypeString, Optional: true},
-		"aggregate":      {Type: schema.TypeString, Optional: true}, // optional
-		"routable_ip":    {Type: schema.TypeBool, Optional: true},
-		"blockstore":     {Type: schema.TypeList, Optional: true, Elem: blockstoreBlock()},
+		"name":                 {Type: schema.TypeString, Required: true},
+		"cores":                {Type: schema.TypeInt, Optional: true},
+		"ram_mb":               {Type: schema.TypeInt, Optional: true},
+		"disk_gb":              {Type: schema.TypeInt, Optional: true},
+		"instantiate_on":       {Type: schema.TypeString, Optional: true},
+		"disk_image":           {Type: schema.TypeString, Optional: true},
+		"power_state":          {Type: schema.TypeString, Optional: true, Default: PowerOn, ValidateFunc: validation.StringInSlice([]string{PowerOn, PowerOff}, false)},
+		"reboot_triggers":      {Type: schema.TypeMap, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		"aggregate":            {Type: schema.TypeString, Optional: true}, // optional
+		"aggregate_preference": {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		"routable_ip":          {Type: schema.TypeBool, Optional: true},
+		"blockstore":           {Type: schema.TypeList, Optional: true, Elem: blockstoreBlock()},
+		"execute":              {Type: schema.TypeList, Optional: true, Elem: executeBlock()},
+		"install":              {Type: schema.TypeList, Optional: true, Elem: installBlock()},
+	}}
+}
+
+func dockerBlock() *schema.Resource {
+	return &schema.Resource{Schema: map[string]*schema.Schema{
+		"name":                 {Type: schema.TypeString, Required: true},
+		"image":                {Type: schema.TypeString, Optional: true}, // registry image
+		"dockerfile":           {Type: schema.TypeString, Optional: true}, // URL, built on the host
+		"disk_image":           {Type: schema.TypeString, Optional: true}, // CloudLab docker image URN
+		"exclusive":            {Type: schema.TypeBool, Optional: true},   // dedicated host vs shared pool
+		"cores":                {Type: schema.TypeInt, Optional: true},
+		"ram_mb":               {Type: schema.TypeInt, Optional: true},
+		"instantiate_on":       {Type: schema.TypeString, Optional: true},
+		"exec_on_start":        {Type: schema.TypeString, Optional: true},
+		"aggregate":            {Type: schema.TypeString, Optional: true}, // optional
+		"aggregate_preference": {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+	}}
+}
+
+// nodeTemplateSchema holds the per-node attributes shared by node_group and
+// the topology node template; kind-specific ones are ignored for other kinds.
+func nodeTemplateSchema() map[string]*schema.Schema {
+	return map[string]*schema.Schema{
+		"kind":                 {Type: schema.TypeString, Required: true, ValidateFunc: validation.StringInSlice([]string{"rawpc", "xenvm", "docker"}, false)},
+		"hardware_type":        {Type: schema.TypeString, Optional: true}, // rawpc
+		"exclusive":            {Type: schema.TypeBool, Optional: true},   // rawpc, docker
+		"cores":                {Type: schema.TypeInt, Optional: true},    // xenvm, docker
+		"ram_mb":               {Type: schema.TypeInt, Optional: true},    // xenvm, docker
+		"disk_gb":              {Type: schema.TypeInt, Optional: true},    // xenvm
+		"instantiate_on":       {Type: schema.TypeString, Optional: true}, // xenvm, docker
+		"image":                {Type: schema.TypeString, Optional: true}, // docker
+		"dockerfile":           {Type: schema.TypeString, Optional: true}, // docker
+		"exec_on_start":        {Type: schema.TypeString, Optional: true}, // docker
+		"disk_image":           {Type: schema.TypeString, Optional: true},
+		"aggregate":            {Type: schema.TypeString, Optional: true}, // optional
+		"aggregate_preference": {Type: schema.TypeList, Optional: true, Elem: &schema.Schema{Type: schema.TypeString}},
+		"routable_ip":          {Type: schema.TypeBool, Optional: true},
+		"blockstore":           {Type: schema.TypeList, Optional: true, Elem: blockstoreBlock()},
+		"execute":              {Type: schema.TypeList, Optional: true, Elem: executeBlock()},
+		"install":              {Type: schema.TypeList, Optional: true, Elem: installBlock()},
+	}
+}
+
+// nodeGroupBlock expands into count nodes named name_prefix0..name_prefixN-1.
+func nodeGroupBlock() *schema.Resource {
+	sc := nodeTemplateSchema()
+	sc["count"] = &schema.Schema{Type: schema.TypeInt, Required: true, ValidateFunc: validation.IntAtLeast(1)}
+	sc["name_prefix"] = &schema.Schema{Type: schema.TypeString, Required: true}
+	sc["lan"] = &schema.Schema{Type: schema.TypeString, Optional: true} // attach every member to this LAN
+	return &schema.Resource{Schema: sc}
+}
+
+// topologyBlock generates nodes and links for a classic topology. Trees are
+// sized by depth/fanout, every other type by node_count.
+func topologyBlock() *schema.Resource {
+	return &schema.Resource{Schema: map[string]*schema.Schema{
+		"type":           {Type: schema.TypeString, Required: true, ValidateFunc: validation.StringInSlice(topologyTypes, false)},
+		"name_prefix":    {Type: schema.TypeString, Required: true},
+		"node_count":     {Type: schema.TypeInt, Optional: true},
+		"depth":          {Type: schema.TypeInt, Optional: true}, // tree
+		"fanout":         {Type: schema.TypeInt, Optional: true}, // tree
+		"node":           {Type: schema.TypeList, Required: true, MinItems: 1, MaxItems: 1, Elem: &schema.Resource{Schema: nodeTemplateSchema()}},
+		"bandwidth_mbps": {Type: schema.TypeInt, Optional: true},
+		"latency_ms":     {Type: schema.TypeInt, Optional: true},
+		"plr":            {Type: schema.TypeFloat, Optional: true},
 	}}
 }
 
 func blockstoreBlock() *schema.Resource {
 	return &schema.Resource{Schema: map[string]*schema.Schema{
-		"name":    {Type: schema.TypeString, Required: true},
-		"mount":   {Type: schema.TypeString, Optional: true},
-		"size_gb": {Type: schema.TypeInt, Required: true},
+		"name":      {Type: schema.TypeString, Required: true},
+		"mount":     {Type: schema.TypeString, Optional: true},
+		"size_gb":   {Type: schema.TypeInt, Optional: true},    // required unless dataset is set
+		"dataset":   {Type: schema.TypeString, Optional: true}, // long-term or image-backed dataset URN
+		"readonly":  {Type: schema.TypeBool, Optional: true},
+		"placement": {Type: schema.TypeString, Optional: true, ValidateFunc: validation.StringInSlice([]string{"sysvol", "nonsysvol", "any"}, false)},
+	}}
+}
+
+func executeBlock() *schema.Resource {
+	return &schema.Resource{Schema: map[string]*schema.Schema{
+		"shell":   {Type: schema.TypeString, Optional: true, Default: "sh", ValidateFunc: validation.StringInSlice([]string{"sh", "bash"}, false)},
+		"command": {Type: schema.TypeString, Required: true},
+	}}
+}
+
+func installBlock() *schema.Resource {
+	return &schema.Resource{Schema: map[string]*schema.Schema{
+		"url":  {Type: schema.TypeString, Required: true},
+		"path": {Type: schema.TypeString, Required: true},
+	}}
+}
+
+func startupStatusBlock() *schema.Resource {
+	return &schema.Resource{Schema: map[string]*schema.Schema{
+		"node":      {Type: schema.TypeString, Computed: true},
+		"status":    {Type: schema.TypeString, Computed: true},
+		"exit_code": {Type: schema.TypeInt, Computed: true}, // -1 until the services exit
+	}}
+}
+
+func stitchedLinkBlock() *schema.Resource {
+	return &schema.Resource{Schema: map[string]*schema.Schema{
+		"name":  {Type: schema.TypeString, Computed: true},
+		"mode":  {Type: schema.TypeString, Computed: true},
+		"sites": {Type: schema.TypeList, Elem: &schema.Schema{Type: schema.TypeString}, Computed: true},
 	}}
 }
 
@@ -77,29 +256,61 @@ func ifaceBlock() *schema.Resource {
 	return &schema.Resource{Schema: map[string]*schema.Schema{
 		"node":   {Type: schema.TypeString, Required: true},
 		"ifname": {Type: schema.TypeString, Optional: true},
+
+		// static address: "10.10.1.1" + netmask, or CIDR "10.10.1.1/24"
+		"ip":      {Type: schema.TypeString, Optional: true},
+		"netmask": {Type: schema.TypeString, Optional: true},
+
+		// outgoing (per-direction) shaping
+		"bandwidth_mbps": {Type: schema.TypeInt, Optional: true},
+		"latency_ms":     {Type: schema.TypeInt, Optional: true},
+		"plr":            {Type: schema.TypeFloat, Optional: true},
 	}}
 }
 
 func linkBlock() *schema.Resource {
-	return &schema.Resource{Schema: map[string]*schema.Schema{
-		"name":      {Type: schema.TypeString, Required: true},
-		"interface": {Type: schema.TypeList, Required: true, MinItems: 2, MaxItems: 2, Elem: ifaceBlock()},
-	}}
+	return &schema.Resource{Schema: withLinkOptions(map[string]*schema.Schema{
+		"name":            {Type: schema.TypeString, Required: true},
+		"bandwidth_mbps":  {Type: schema.TypeInt, Optional: true},
+		"latency_ms":      {Type: schema.TypeInt, Optional: true},
+		"plr":             {Type: schema.TypeFloat, Optional: true},
+		"endnode_shaping": {Type: schema.TypeBool, Optional: true},   // shape on the nodes, no delay node
+		"subnet":          {Type: schema.TypeString, Optional: true}, // CIDR; unaddressed interfaces are allocated from it
+		"interface":       {Type: schema.TypeList, Required: true, MinItems: 2, MaxItems: 2, Elem: ifaceBlock()},
+	})}
 }
 
 func lanBlock() *schema.Resource {
-	return &schema.Resource{Schema: map[string]*schema.Schema{
-		"name":      {Type: schema.TypeString, Required: true},
-		"interface": {Type: schema.TypeList, Required: true, MinItems: 2, Elem: ifaceBlock()},
-	}}
+	return &schema.Resource{Schema: withLinkOptions(map[string]*schema.Schema{
+		"name":                {Type: schema.TypeString, Required: true},
+		"bandwidth_mbps":      {Type: schema.TypeInt, Optional: true},
+		"latency_ms":          {Type: schema.TypeInt, Optional: true},
+		"plr":                 {Type: schema.TypeFloat, Optional: true},
+		"endnode_shaping":     {Type: schema.TypeBool, Optional: true},   // shape on the nodes, no delay node
+		"subnet":              {Type: schema.TypeString, Optional: true}, // CIDR; unaddressed interfaces are allocated from it
+		"shared_vlan_create":  {Type: schema.TypeString, Optional: true},
+		"shared_vlan_connect": {Type: schema.TypeString, Optional: true},
+		"interface":           {Type: schema.TypeList, Required: true, MinItems: 1, Elem: ifaceBlock()},
+	})}
 }
 
 func bridgedLinkBlock() *schema.Resource {
-	return &schema.Resource{Schema: map[string]*schema.Schema{
+	return &schema.Resource{Schema: withLinkOptions(map[string]*schema.Schema{
 		"name":           {Type: schema.TypeString, Required: true},
 		"bandwidth_mbps": {Type: schema.TypeInt, Optional: true},
 		"latency_ms":     {Type: schema.TypeInt, Optional: true},
 		"plr":            {Type: schema.TypeFloat, Optional: true},
 		"interface":      {Type: schema.TypeList, Required: true, MinItems: 2, Elem: ifaceBlock()},
-	}}
+	})}
+}
+
+// withLinkOptions adds the options shared by link, lan and bridged_link.
+func withLinkOptions(sc map[string]*schema.Schema) map[string]*schema.Schema {
+	sc["vlan_tagging"] = &schema.Schema{Type: schema.TypeBool, Optional: true}
+	sc["link_multiplexing"] = &schema.Schema{Type: schema.TypeBool, Optional: true}
+	sc["best_effort"] = &schema.Schema{Type: schema.TypeBool, Optional: true}
+	sc["trivial_ok"] = &schema.Schema{Type: schema.TypeBool, Optional: true}
+	sc["stitching"] = &schema.Schema{Type: schema.TypeString, Optional: true, ValidateFunc: validation.StringInSlice(stitchingModes, false)}
+	sc["protocol"] = &schema.Schema{Type: schema.TypeString, Optional: true, ValidateFunc: validation.StringInSlice([]string{"ethernet", "infiniband"}, false)}
+	return sc
 }
@@ -0,0 +1,92 @@
+package experiment
+
+import (
+	"fmt"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+func ifaceShaped(i model.Iface) bool {
+	return i.Bandwidth != nil || i.Latency != nil || i.Plr != nil
+}
+
+func linkShaped(l model.Link) bool {
+	if l.Bandwidth != nil || l.Latency != nil || l.Plr != nil {
+		return true
+	}
+	for _, i := range l.Interfaces {
+		if ifaceShaped(i) {
+			return true
+		}
+	}
+	return false
+}
+
+func validateShaping(l model.Link) error {
+	if err := checkShapingValues(l.Kind, l.Name, l.Bandwidth, l.Latency, l.Plr); err != nil {
+		return err
+	}
+	for _, i := range l.Interfaces {
+		if !ifaceShaped(i) {
+			continue
+		}
+		// The bridge node shapes both directions identically.
+		if l.Kind == "bridged_link" {
+			return fmt.Errorf("bridged_link %q does not support per-interface shaping (interface on %q)", l.Name, i.Node)
+		}
+		if err := checkShapingValues(l.Kind, l.Name+"/"+i.Node, i.Bandwidth, i.Latency, i.Plr); err != nil {
+			return err
+		}
+	}
+	if l.EndnodeShaping != nil && *l.EndnodeShaping && !linkShaped(l) {
+		return fmt.Errorf("%s %q sets endnode_shaping without any bandwidth_mbps, latency_ms or plr", l.Kind, l.Name)
+	}
+	return nil
+}
+
+func checkShapingValues(kind, name string, bw, lat *int, plr *float64) error {
+	if bw != nil && *bw < 1 {
+		return fmt.Errorf("%s %q bandwidth_mbps must be >= 1", kind, name)
+	}
+	if lat != nil && *lat < 0 {
+		return fmt.Errorf("%s %q latency_ms must be >= 0", kind, name)
+	}
+	if plr != nil && (*plr < 0.0 || *plr > 1.0) {
+		return fmt.Errorf("%s %q plr must be between 0.0 and 1.0", kind, name)
+	}
+	return nil
+}
+
+// applyShaping returns a copy of spec.Links with DelayNode set the way
+// CloudLab will realize the shaping: bridged links always get their bridge
+// node; shaped links and lans get a delay node unless endnode shaping was
+// requested or an endpoint is a VM (VMs are always shaped on the host).
+func applyShaping(spec model.ExperimentSpec) []model.Link {
+	kinds := map[string]string{}
+	for _, n := range spec.Nodes {
+		kinds[n.Name] = n.Kind
+	}
+	out := make([]model.Link, len(spec.Links))
+	for i, l := range spec.Links {
+		l.DelayNode = needsDelayNode(l, kinds)
+		out[i] = l
+	}
+	return out
+}
+
+// needsDelayNode reports whether CloudLab will insert a delay (or bridge)
+// node for l, given node name -> kind.
+func needsDelayNode(l model.Link, kinds map[string]string) bool {
+	switch {
+	case l.Kind == "bridged_link":
+		return true
+	case !linkShaped(l), l.EndnodeShaping != nil && *l.EndnodeShaping:
+		return false
+	}
+	for _, ifc := range l.Interfaces {
+		if kinds[ifc.Node] != "rawpc" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package experiment
+
+import (
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+func intp(v int) *int           { return &v }
+func floatp(v float64) *float64 { return &v }
+func boolp(v bool) *bool        { return &v }
+
+func TestCheckShapingValues(t *testing.T) {
+	tests := []struct {
+		name    string
+		bw, lat *int
+		plr     *float64
+		wantErr bool
+	}{
+		{name: "unset"},
+		{name: "valid", bw: intp(100), lat: intp(0), plr: floatp(0.5)},
+		{name: "zero bandwidth", bw: intp(0), wantErr: true},
+		{name: "negative latency", lat: intp(-1), wantErr: true},
+		{name: "plr above one", plr: floatp(1.5), wantErr: true},
+		{name: "plr negative", plr: floatp(-0.1), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkShapingValues("link", "l0", tt.bw, tt.lat, tt.plr)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Unset nested attributes read as zero; a bridged_link without shaping must
+// still validate.
+func TestExpandBridgedUnsetShaping(t *testing.T) {
+	links := expandBridged([]interface{}{map[string]interface{}{
+		"name":           "br0",
+		"bandwidth_mbps": 0,
+		"latency_ms":     0,
+		"plr":            0.0,
+		"interface": []interface{}{
+			map[string]interface{}{"node": "a"},
+			map[string]interface{}{"node": "b"},
+		},
+	}})
+	if len(links) != 1 {
+		t.Fatalf("got %d links", len(links))
+	}
+	l := links[0]
+	if l.Bandwidth != nil || l.Latency != nil || l.Plr != nil {
+		t.Fatalf("unset shaping expanded to %v/%v/%v", l.Bandwidth, l.Latency, l.Plr)
+	}
+	if err := validateShaping(l); err != nil {
+		t.Fatalf("validateShaping: %v", err)
+	}
+}
+
+// Negative shaping values must reach validation instead of reading as unset.
+func TestExpandNegativeShapingRejected(t *testing.T) {
+	ifaces := func(bw int) []interface{} {
+		return []interface{}{
+			map[string]interface{}{"node": "a", "bandwidth_mbps": bw},
+			map[string]interface{}{"node": "b"},
+		}
+	}
+	tests := []struct {
+		name string
+		link model.Link
+	}{
+		{"bridged plr", expandBridged([]interface{}{map[string]interface{}{"name": "br0", "plr": -0.1, "interface": ifaces(0)}})[0]},
+		{"link latency", expandLinks("link", []interface{}{map[string]interface{}{"name": "l0", "latency_ms": -5, "interface": ifaces(0)}})[0]},
+		{"lan bandwidth", expandLinks("lan", []interface{}{map[string]interface{}{"name": "lan0", "bandwidth_mbps": -1, "interface": ifaces(0)}})[0]},
+		{"interface bandwidth", expandLinks("lan", []interface{}{map[string]interface{}{"name": "lan0", "interface": ifaces(-100)}})[0]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateShaping(tt.link); err == nil {
+				t.Fatalf("expected an error for %+v", tt.link)
+			}
+		})
+	}
+}
+
+func TestNeedsDelayNode(t *testing.T) {
+	kinds := map[string]string{"a": "rawpc", "b": "rawpc", "vm": "xenvm"}
+	tests := []struct {
+		name string
+		link model.Link
+		want bool
+	}{
+		{"unshaped", model.Link{Kind: "link", Interfaces: []model.Iface{{Node: "a"}, {Node: "b"}}}, false},
+		{"shaped", model.Link{Kind: "link", Bandwidth: intp(100), Interfaces: []model.Iface{{Node: "a"}, {Node: "b"}}}, true},
+		{"endnode", model.Link{Kind: "link", Bandwidth: intp(100), EndnodeShaping: boolp(true), Interfaces: []model.Iface{{Node: "a"}, {Node: "b"}}}, false},
+		{"vm endpoint", model.Link{Kind: "link", Bandwidth: intp(100), Interfaces: []model.Iface{{Node: "a"}, {Node: "vm"}}}, false},
+		{"bridged", model.Link{Kind: "bridged_link", Interfaces: []model.Iface{{Node: "a"}, {Node: "b"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsDelayNode(tt.link, kinds); got != tt.want {
+				t.Fatalf("needsDelayNode = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
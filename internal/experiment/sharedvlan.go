@@ -0,0 +1,61 @@
+package experiment
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+// CloudLab shared VLAN names: letters, digits, '-' and '_', at most 32 chars.
+var sharedVlanName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,31}$`)
+
+// A lan on a shared VLAN may have a single local member; the rest of the
+// segment lives in other experiments.
+func isSharedVlan(l model.Link) bool {
+	return l.SharedVlanCreate != "" || l.SharedVlanConnect != ""
+}
+
+func validateSharedVlans(links []model.Link) error {
+	created := map[string]string{}
+	for _, l := range links {
+		if !isSharedVlan(l) {
+			continue
+		}
+		if l.Kind != "lan" {
+			return fmt.Errorf("%s %q: shared VLANs are only supported on lan", l.Kind, l.Name)
+		}
+		if l.SharedVlanCreate != "" && l.SharedVlanConnect != "" {
+			return fmt.Errorf("lan %q cannot both create and connect to a shared VLAN", l.Name)
+		}
+		name := l.SharedVlanCreate + l.SharedVlanConnect
+		if !sharedVlanName.MatchString(name) {
+			return fmt.Errorf("lan %q shared VLAN name %q must be 1-32 letters, digits, '-' or '_'", l.Name, name)
+		}
+		if l.SharedVlanCreate != "" {
+			if other, dup := created[name]; dup {
+				return fmt.Errorf("lans %q and %q both create shared VLAN %q", other, l.Name, name)
+			}
+			created[name] = l.Name
+		}
+	}
+	for _, l := range links {
+		if lan, ok := created[l.SharedVlanConnect]; ok {
+			return fmt.Errorf("lan %q connects to shared VLAN %q created by lan %q in the same experiment; use one lan", l.Name, l.SharedVlanConnect, lan)
+		}
+	}
+	return nil
+}
+
+// flattenSharedVlans maps lan name -> shared VLAN name for VLANs this
+// experiment creates. Referencing it from another experiment's
+// shared_vlan_connect orders that experiment after this one.
+func flattenSharedVlans(s model.ExperimentSpec) map[string]string {
+	out := map[string]string{}
+	for _, l := range s.Links {
+		if l.SharedVlanCreate != "" {
+			out[l.Name] = l.SharedVlanCreate
+		}
+	}
+	return out
+}
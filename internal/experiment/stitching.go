@@ -0,0 +1,109 @@
+package experiment
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/validation"
+)
+
+const (
+	StitchingStitched = "stitched"
+	StitchingEGRE     = "egre"
+	StitchingGRE      = "gre"
+)
+
+var stitchingModes = []string{StitchingStitched, StitchingEGRE, StitchingGRE}
+
+// linkSites returns the sorted distinct aggregates of l's endpoints and
+// whether any endpoint has no aggregate set.
+func linkSites(l model.Link, aggs map[string]string) (sites []string, unset bool) {
+	seen := map[string]bool{}
+	for _, ifc := range l.Interfaces {
+		a := aggs[ifc.Node]
+		if a == "" {
+			unset = true
+			continue
+		}
+		if !seen[a] {
+			seen[a] = true
+			sites = append(sites, a)
+		}
+	}
+	sort.Strings(sites)
+	return sites, unset
+}
+
+// validateStitching requires cross-aggregate links to say how they cross
+// (stitching) and rejects combinations the portal cannot realize.
+func validateStitching(s model.ExperimentSpec) error {
+	aggs := map[string]string{}
+	kinds := map[string]string{}
+	for _, n := range s.Nodes {
+		aggs[n.Name] = n.Aggregate
+		kinds[n.Name] = n.Kind
+	}
+	for _, l := range s.Links {
+		sites, unset := linkSites(l, aggs)
+		cross := len(sites) > 1
+		if !cross {
+			if l.Stitching != "" {
+				return fmt.Errorf("%s %q sets stitching but all endpoints are on one aggregate", l.Kind, l.Name)
+			}
+			continue
+		}
+		if unset {
+			return fmt.Errorf("%s %q spans aggregates; set aggregate on every endpoint", l.Kind, l.Name)
+		}
+		if l.Kind != "link" {
+			return fmt.Errorf("%s %q spans aggregates %v; only point-to-point links can cross sites", l.Kind, l.Name, sites)
+		}
+		if l.Stitching == "" {
+			return fmt.Errorf("link %q connects %s and %s; set stitching to one of %v", l.Name, sites[0], sites[1], stitchingModes)
+		}
+		for _, a := range sites {
+			if !validation.StitchingDomains[validation.Aggregates[a]] {
+				return fmt.Errorf("link %q: aggregate %q does not support %s links", l.Name, a, l.Stitching)
+			}
+		}
+		for _, ifc := range l.Interfaces {
+			if k := kinds[ifc.Node]; k != "rawpc" && k != "xenvm" {
+				return fmt.Errorf("link %q: cross-site endpoint %q must be a rawpc or xenvm (got %s)", l.Name, ifc.Node, k)
+			}
+		}
+		// No delay node can sit across sites, and tunnels carry no VLAN tags.
+		if linkShaped(l) && !isSet(l.EndnodeShaping) {
+			return fmt.Errorf("link %q: cross-site shaping needs endnode_shaping", l.Name)
+		}
+		if isSet(l.TrivialOK) {
+			return fmt.Errorf("link %q: a cross-site link cannot be trivial_ok", l.Name)
+		}
+		if l.Stitching != StitchingStitched && (isSet(l.VlanTagging) || isSet(l.LinkMultiplexing)) {
+			return fmt.Errorf("link %q: %s tunnels do not support vlan_tagging or link_multiplexing", l.Name, l.Stitching)
+		}
+	}
+	return nil
+}
+
+// flattenStitchedLinks lists every cross-aggregate link with its mode and
+// the sites it joins.
+func flattenStitchedLinks(s model.ExperimentSpec) []map[string]interface{} {
+	aggs := map[string]string{}
+	for _, n := range s.Nodes {
+		aggs[n.Name] = n.Aggregate
+	}
+	out := []map[string]interface{}{}
+	for _, l := range s.Links {
+		if l.Stitching == "" {
+			continue
+		}
+		sites, _ := linkSites(l, aggs)
+		names := make([]interface{}, 0, len(sites))
+		for _, a := range sites {
+			names = append(names, validation.Aggregates[a])
+		}
+		out = append(out, map[string]interface{}{"name": l.Name, "mode": l.Stitching, "sites": names})
+	}
+	return out
+}
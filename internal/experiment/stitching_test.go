@@ -0,0 +1,57 @@
+package experiment
+
+import (
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+const (
+	utah    = "urn:publicid:IDN+utah.cloudlab.us+authority+cm"
+	clemson = "urn:publicid:IDN+clemson.cloudlab.us+authority+cm"
+	apt     = "urn:publicid:IDN+apt.emulab.net+authority+cm"
+)
+
+func TestValidateStitching(t *testing.T) {
+	nodes := []model.Node{
+		{Kind: "rawpc", Name: "a", Aggregate: utah},
+		{Kind: "rawpc", Name: "b", Aggregate: clemson},
+		{Kind: "rawpc", Name: "c", Aggregate: utah},
+		{Kind: "rawpc", Name: "d"},
+		{Kind: "rawpc", Name: "e", Aggregate: apt},
+		{Kind: "docker", Name: "f", Aggregate: clemson},
+	}
+	ifaces := func(names ...string) []model.Iface {
+		var out []model.Iface
+		for _, n := range names {
+			out = append(out, model.Iface{Node: n})
+		}
+		return out
+	}
+	tests := []struct {
+		name    string
+		link    model.Link
+		wantErr bool
+	}{
+		{name: "same site", link: model.Link{Kind: "link", Name: "l", Interfaces: ifaces("a", "c")}},
+		{name: "same site with stitching", link: model.Link{Kind: "link", Name: "l", Stitching: StitchingGRE, Interfaces: ifaces("a", "c")}, wantErr: true},
+		{name: "stitched", link: model.Link{Kind: "link", Name: "l", Stitching: StitchingStitched, Interfaces: ifaces("a", "b")}},
+		{name: "cross site without mode", link: model.Link{Kind: "link", Name: "l", Interfaces: ifaces("a", "b")}, wantErr: true},
+		{name: "cross site lan", link: model.Link{Kind: "lan", Name: "l", Stitching: StitchingGRE, Interfaces: ifaces("a", "b", "c")}, wantErr: true},
+		{name: "unset aggregate", link: model.Link{Kind: "link", Name: "l", Stitching: StitchingGRE, Interfaces: ifaces("a", "b", "d")}, wantErr: true},
+		{name: "site without stitching", link: model.Link{Kind: "link", Name: "l", Stitching: StitchingGRE, Interfaces: ifaces("a", "e")}, wantErr: true},
+		{name: "docker endpoint", link: model.Link{Kind: "link", Name: "l", Stitching: StitchingGRE, Interfaces: ifaces("a", "f")}, wantErr: true},
+		{name: "shaped without endnode", link: model.Link{Kind: "link", Name: "l", Stitching: StitchingStitched, Bandwidth: intp(100), Interfaces: ifaces("a", "b")}, wantErr: true},
+		{name: "shaped on endnodes", link: model.Link{Kind: "link", Name: "l", Stitching: StitchingStitched, Bandwidth: intp(100), EndnodeShaping: boolp(true), Interfaces: ifaces("a", "b")}},
+		{name: "trivial", link: model.Link{Kind: "link", Name: "l", Stitching: StitchingStitched, TrivialOK: boolp(true), Interfaces: ifaces("a", "b")}, wantErr: true},
+		{name: "gre with vlan tagging", link: model.Link{Kind: "link", Name: "l", Stitching: StitchingGRE, VlanTagging: boolp(true), Interfaces: ifaces("a", "b")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateStitching(model.ExperimentSpec{Nodes: nodes, Links: []model.Link{tt.link}})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package experiment
+
+import (
+	"fmt"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+const (
+	TopologyStar     = "star"
+	TopologyRing     = "ring"
+	TopologyLine     = "line"
+	TopologyTree     = "tree"
+	TopologyFullMesh = "full_mesh"
+)
+
+var topologyTypes = []string{TopologyStar, TopologyRing, TopologyLine, TopologyTree, TopologyFullMesh}
+
+// expandTopologies appends the nodes and point-to-point links of every
+// topology block to spec. Nodes are named like node_group members; links are
+// named "<a>-<b>" after their endpoints.
+func expandTopologies(spec *model.ExperimentSpec, v interface{}) error {
+	for _, it := range toList(v) {
+		m := it.(map[string]interface{})
+		typ := s(m["type"])
+		prefix := s(m["name_prefix"])
+
+		count, edges, err := topologyEdges(typ, m)
+		if err != nil {
+			return fmt.Errorf("topology %q: %w", prefix, err)
+		}
+
+		tmpl := map[string]interface{}{}
+		if l := toList(m["node"]); len(l) > 0 && l[0] != nil {
+			tmpl = l[0].(map[string]interface{})
+		}
+		kind := s(tmpl["kind"])
+		for i := 0; i < count; i++ {
+			spec.Nodes = append(spec.Nodes, templateNode(kind, groupMemberName(prefix, i), tmpl))
+		}
+
+		for _, e := range edges {
+			a, b := groupMemberName(prefix, e[0]), groupMemberName(prefix, e[1])
+			spec.Links = append(spec.Links, model.Link{
+				Kind:       "link",
+				Name:       a + "-" + b,
+				Interfaces: []model.Iface{{Node: a}, {Node: b}},
+				Bandwidth:  pNonZeroInt(m, "bandwidth_mbps"),
+				Latency:    pNonZeroInt(m, "latency_ms"),
+				Plr:        pNonZeroFloat(m, "plr"),
+			})
+		}
+	}
+	return nil
+}
+
+// topologyEdges returns the node count and the member index pairs to link.
+func topologyEdges(typ string, m map[string]interface{}) (int, [][2]int, error) {
+	var edges [][2]int
+	if typ == TopologyTree {
+		depth, _ := m["depth"].(int)
+		fanout, _ := m["fanout"].(int)
+		if depth < 1 || fanout < 1 {
+			return 0, nil, fmt.Errorf("tree requires depth >= 1 and fanout >= 1")
+		}
+		// Breadth-first numbering: children of i are i*fanout+1 .. i*fanout+fanout.
+		count, level := 0, 1
+		for d := 0; d < depth; d++ {
+			count += level
+			level *= fanout
+		}
+		for c := 1; c < count; c++ {
+			edges = append(edges, [2]int{(c - 1) / fanout, c})
+		}
+		return count, edges, nil
+	}
+
+	n, _ := m["node_count"].(int)
+	min := 2
+	if typ == TopologyRing {
+		min = 3
+	}
+	if n < min {
+		return 0, nil, fmt.Errorf("%s requires node_count >= %d", typ, min)
+	}
+	switch typ {
+	case TopologyStar:
+		for i := 1; i < n; i++ {
+			edges = append(edges, [2]int{0, i})
+		}
+	case TopologyLine, TopologyRing:
+		for i := 0; i+1 < n; i++ {
+			edges = append(edges, [2]int{i, i + 1})
+		}
+		if typ == TopologyRing {
+			edges = append(edges, [2]int{n - 1, 0})
+		}
+	case TopologyFullMesh:
+		for i := 0; i < n; i++ {
+			for j := i + 1; j < n; j++ {
+				edges = append(edges, [2]int{i, j})
+			}
+		}
+	default:
+		return 0, nil, fmt.Errorf("unknown topology type %q", typ)
+	}
+	return n, edges, nil
+}
@@ -0,0 +1,72 @@
+package experiment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+func TestTopologyEdges(t *testing.T) {
+	tests := []struct {
+		name      string
+		typ       string
+		m         map[string]interface{}
+		wantCount int
+		wantEdges [][2]int
+		wantErr   bool
+	}{
+		{name: "star", typ: TopologyStar, m: map[string]interface{}{"node_count": 4},
+			wantCount: 4, wantEdges: [][2]int{{0, 1}, {0, 2}, {0, 3}}},
+		{name: "line", typ: TopologyLine, m: map[string]interface{}{"node_count": 3},
+			wantCount: 3, wantEdges: [][2]int{{0, 1}, {1, 2}}},
+		{name: "ring", typ: TopologyRing, m: map[string]interface{}{"node_count": 3},
+			wantCount: 3, wantEdges: [][2]int{{0, 1}, {1, 2}, {2, 0}}},
+		{name: "full mesh", typ: TopologyFullMesh, m: map[string]interface{}{"node_count": 3},
+			wantCount: 3, wantEdges: [][2]int{{0, 1}, {0, 2}, {1, 2}}},
+		{name: "tree", typ: TopologyTree, m: map[string]interface{}{"depth": 2, "fanout": 2},
+			wantCount: 3, wantEdges: [][2]int{{0, 1}, {0, 2}}},
+		{name: "deep tree", typ: TopologyTree, m: map[string]interface{}{"depth": 3, "fanout": 2},
+			wantCount: 7, wantEdges: [][2]int{{0, 1}, {0, 2}, {1, 3}, {1, 4}, {2, 5}, {2, 6}}},
+		{name: "ring too small", typ: TopologyRing, m: map[string]interface{}{"node_count": 2}, wantErr: true},
+		{name: "star too small", typ: TopologyStar, m: map[string]interface{}{"node_count": 1}, wantErr: true},
+		{name: "tree without fanout", typ: TopologyTree, m: map[string]interface{}{"depth": 2}, wantErr: true},
+		{name: "unknown", typ: "hypercube", m: map[string]interface{}{"node_count": 4}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, edges, err := topologyEdges(tt.typ, tt.m)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if n != tt.wantCount {
+				t.Errorf("count = %d, want %d", n, tt.wantCount)
+			}
+			if !reflect.DeepEqual(edges, tt.wantEdges) {
+				t.Errorf("edges = %v, want %v", edges, tt.wantEdges)
+			}
+		})
+	}
+}
+
+func TestValidateSpecRejectsTopologyLinkNameClash(t *testing.T) {
+	spec := model.ExperimentSpec{}
+	topo := []interface{}{map[string]interface{}{
+		"type": TopologyLine, "name_prefix": "n", "node_count": 2,
+		"node": []interface{}{map[string]interface{}{"kind": "rawpc"}},
+	}}
+	if err := expandTopologies(&spec, topo); err != nil {
+		t.Fatal(err)
+	}
+	if err := validateSpec(spec); err != nil {
+		t.Fatalf("generated topology: %v", err)
+	}
+	declared := spec.Links[0]
+	spec.Links = append(spec.Links, model.Link{Kind: "lan", Name: declared.Name, Interfaces: declared.Interfaces})
+	if err := validateSpec(spec); err == nil {
+		t.Fatalf("expected duplicate link name %q to be rejected", declared.Name)
+	}
+}
@@ -0,0 +1,248 @@
+package experiment
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+const (
+	PowerOn  = "on"
+	PowerOff = "off"
+)
+
+// inPlaceNodeKeys are node attributes an update can change without
+// replacing the experiment.
+var inPlaceNodeKeys = []string{"disk_image", "power_state", "reboot_triggers"}
+
+// customizeDiff forces replacement when a rawpc, xenvm, link or lan change
+// can't be done in place (see modifiable).
+func customizeDiff(ctx context.Context, d *schema.ResourceDiff, meta interface{}) error {
+	if d.Id() == "" {
+		return nil
+	}
+	for _, key := range []string{"rawpc", "xenvm", "link", "lan"} {
+		if !d.HasChange(key) {
+			continue
+		}
+		o, n := d.GetChange(key)
+		if !modifiable(key, toList(o), toList(n)) {
+			if err := d.ForceNew(key); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func withoutInPlace(v interface{}) map[string]interface{} {
+	out := map[string]interface{}{}
+	m, _ := v.(map[string]interface{})
+	for k, val := range m {
+		out[k] = val
+	}
+	for _, k := range inPlaceNodeKeys {
+		delete(out, k)
+	}
+	return out
+}
+
+// Applies in-place changes: modifies the experiment when nodes, links or
+// link members were added or removed, reloads nodes whose disk_image
+// changed, reboots nodes whose reboot_triggers (or the experiment's)
+// changed, waits for them to return to wait_for_status, then applies
+// power_state. Other nodes are not touched.
+func resourceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	// Keep the prior state if any step fails, so the next plan still shows
+	// the changes that were not applied.
+	d.Partial(true)
+
+	expName := d.Id()
+	project := d.Get("project").(string)
+	if project == "" {
+		project = cfg.Project
+	}
+	oldSpec, err := buildSpec(priorState{d})
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	newSpec, err := buildSpec(d)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	to := d.Timeout(schema.TimeoutUpdate)
+	if to == 0 {
+		to = 30 * time.Minute
+	}
+	ctxUpdate, cancel := context.WithTimeout(ctx, to)
+	defer cancel()
+
+	waitFor := canon(d.Get("wait_for_status").(string))
+	if topologyChanged(oldSpec, newSpec) {
+		placed, err := modifyTopology(ctxUpdate, d, cfg, project, expName, oldSpec, newSpec, waitFor)
+		if err != nil {
+			return diag.FromErr(err)
+		}
+		_ = d.Set("addresses", flattenAddresses(placed))
+		_ = d.Set("shared_vlans", flattenSharedVlans(placed))
+		_ = d.Set("stitched_links", flattenStitchedLinks(placed))
+		_ = d.Set("node_aggregates", flattenNodeAggregates(placed))
+	}
+
+	byImage := reloadsByImage(oldSpec, newSpec)
+	reloading := map[string]bool{}
+	for image, nodes := range byImage {
+		tflog.Info(ctx, "reloading nodes", map[string]any{"experiment": expName, "nodes": nodes, "image": image})
+		if _, err := portalclient.Reload(cfg.Client, project, expName, nodes, image); err != nil {
+			return diag.FromErr(err)
+		}
+		for _, n := range nodes {
+			reloading[n] = true
+		}
+	}
+
+	// A reload already reboots the node.
+	var reboot []string
+	for _, n := range rebootTargets(oldSpec, newSpec, d.HasChange("reboot_triggers")) {
+		if !reloading[n] {
+			reboot = append(reboot, n)
+		}
+	}
+	if len(reboot) > 0 {
+		tflog.Info(ctx, "rebooting nodes", map[string]any{"experiment": expName, "nodes": reboot})
+		if _, err := portalclient.Reboot(cfg.Client, project, expName, reboot); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if len(reloading) > 0 || len(reboot) > 0 {
+		busy := append([]string(nil), reboot...)
+		for n := range reloading {
+			busy = append(busy, n)
+		}
+		sort.Strings(busy)
+		if err := waitForNodes(ctxUpdate, cfg, project, expName, newSpec, busy, waitFor); err != nil {
+			return diag.FromErr(err)
+		}
+	}
+
+	if err := applyPowerStates(ctx, cfg, project, expName, afterReload(oldSpec, reloading), newSpec); err != nil {
+		return diag.FromErr(err)
+	}
+	d.Partial(false)
+	return resourceRead(ctx, d, meta)
+}
+
+// rebootableKinds are the node kinds portal.reboot accepts; the others
+// (remote_blockstore) are not machines.
+var rebootableKinds = map[string]bool{"rawpc": true, "xenvm": true, "docker": true}
+
+// rebootTargets lists rebootable nodes present in both specs that are (and
+// stay) powered on and whose reboot_triggers changed; all of them when the
+// experiment-level triggers changed.
+func rebootTargets(oldSpec, newSpec model.ExperimentSpec, all bool) []string {
+	old := map[string]model.Node{}
+	for _, n := range oldSpec.Nodes {
+		old[n.Name] = n
+	}
+	var out []string
+	for _, n := range newSpec.Nodes {
+		o, ok := old[n.Name]
+		if !ok || !rebootableKinds[n.Kind] || n.PowerState == PowerOff || o.PowerState == PowerOff {
+			continue
+		}
+		if all || !reflect.DeepEqual(o.RebootTriggers, n.RebootTriggers) {
+			out = append(out, n.Name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// reloadsByImage groups nodes present in both specs whose disk image
+// changed to another image by that image. Clearing disk_image is not a
+// reload (see modifiable).
+func reloadsByImage(oldSpec, newSpec model.ExperimentSpec) map[string][]string {
+	old := map[string]model.Node{}
+	for _, n := range oldSpec.Nodes {
+		old[n.Name] = n
+	}
+	out := map[string][]string{}
+	for _, n := range newSpec.Nodes {
+		if o, ok := old[n.Name]; ok && n.DiskImage != "" && o.DiskImage != n.DiskImage {
+			out[n.DiskImage] = append(out[n.DiskImage], n.Name)
+		}
+	}
+	return out
+}
+
+// afterReload returns spec with the reloaded nodes powered on, which is
+// where a reload leaves them even if they were off.
+func afterReload(spec model.ExperimentSpec, reloaded map[string]bool) model.ExperimentSpec {
+	spec.Nodes = append([]model.Node(nil), spec.Nodes...)
+	for i := range spec.Nodes {
+		if reloaded[spec.Nodes[i].Name] {
+			spec.Nodes[i].PowerState = PowerOn
+		}
+	}
+	return spec
+}
+
+// applyPowerStates powers nodes on or off where their power_state differs
+// from oldSpec (an empty oldSpec means every node is currently on).
+func applyPowerStates(ctx context.Context, cfg *portalclient.Config, project, expName string, oldSpec, newSpec model.ExperimentSpec) error {
+	on, off := powerChanges(oldSpec, newSpec)
+	for _, batch := range []struct {
+		nodes []string
+		on    bool
+	}{{on, true}, {off, false}} {
+		if len(batch.nodes) == 0 {
+			continue
+		}
+		tflog.Info(ctx, "setting node power", map[string]any{"experiment": expName, "nodes": batch.nodes, "on": batch.on})
+		if _, err := portalclient.Power(cfg.Client, project, expName, batch.nodes, batch.on); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// powerChanges lists the nodes to power on and off to go from oldSpec's
+// power states to newSpec's.
+func powerChanges(oldSpec, newSpec model.ExperimentSpec) (on, off []string) {
+	cur := map[string]string{}
+	for _, n := range oldSpec.Nodes {
+		cur[n.Name] = n.PowerState
+	}
+	for _, n := range newSpec.Nodes {
+		was := cur[n.Name]
+		if was == "" {
+			was = PowerOn
+		}
+		want := n.PowerState
+		if want == "" {
+			want = PowerOn
+		}
+		switch {
+		case want == was:
+		case want == PowerOn:
+			on = append(on, n.Name)
+		default:
+			off = append(off, n.Name)
+		}
+	}
+	sort.Strings(on)
+	sort.Strings(off)
+	return on, off
+}
@@ -0,0 +1,76 @@
+package experiment
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
+)
+
+func TestRebootTargets(t *testing.T) {
+	oldSpec := model.ExperimentSpec{Nodes: []model.Node{
+		{Kind: "rawpc", Name: "a", RebootTriggers: map[string]string{"v": "1"}},
+		{Kind: "xenvm", Name: "b"},
+		{Kind: "rawpc", Name: "off", PowerState: PowerOff},
+		{Kind: "remote_blockstore", Name: "a-data"},
+		{Kind: "rawpc", Name: "gone"},
+	}}
+	newSpec := model.ExperimentSpec{Nodes: []model.Node{
+		{Kind: "rawpc", Name: "a", RebootTriggers: map[string]string{"v": "2"}},
+		{Kind: "xenvm", Name: "b"},
+		{Kind: "rawpc", Name: "off", PowerState: PowerOff},
+		{Kind: "remote_blockstore", Name: "a-data"},
+		{Kind: "docker", Name: "new"},
+	}}
+	tests := []struct {
+		name string
+		all  bool
+		want []string
+	}{
+		{name: "node triggers", want: []string{"a"}},
+		{name: "experiment triggers", all: true, want: []string{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rebootTargets(oldSpec, newSpec, tt.all); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("rebootTargets = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReloadsByImage(t *testing.T) {
+	oldSpec := model.ExperimentSpec{Nodes: []model.Node{
+		{Name: "a", DiskImage: "img1"}, {Name: "b", DiskImage: "img1"}, {Name: "c", DiskImage: "img1"},
+	}}
+	newSpec := model.ExperimentSpec{Nodes: []model.Node{
+		{Name: "a", DiskImage: "img2"}, {Name: "b", DiskImage: "img1"}, {Name: "c"}, {Name: "d", DiskImage: "img2"},
+	}}
+	want := map[string][]string{"img2": {"a"}}
+	if got := reloadsByImage(oldSpec, newSpec); !reflect.DeepEqual(got, want) {
+		t.Fatalf("reloadsByImage = %v, want %v", got, want)
+	}
+}
+
+// A reload boots a powered-off node; power_state off must be applied again.
+func TestAfterReload(t *testing.T) {
+	oldSpec := model.ExperimentSpec{Nodes: []model.Node{
+		{Name: "a", DiskImage: "img1", PowerState: PowerOff},
+		{Name: "b", DiskImage: "img1", PowerState: PowerOff},
+	}}
+	newSpec := model.ExperimentSpec{Nodes: []model.Node{
+		{Name: "a", DiskImage: "img2", PowerState: PowerOff},
+		{Name: "b", DiskImage: "img1", PowerState: PowerOff},
+	}}
+	cur := afterReload(oldSpec, map[string]bool{"a": true})
+	if cur.Nodes[0].PowerState != PowerOn || cur.Nodes[1].PowerState != PowerOff {
+		t.Fatalf("afterReload = %+v", cur.Nodes)
+	}
+	if oldSpec.Nodes[0].PowerState != PowerOff {
+		t.Fatal("afterReload modified its argument")
+	}
+	_, off := powerChanges(cur, newSpec)
+	if !reflect.DeepEqual(off, []string{"a"}) {
+		t.Fatalf("power off = %v, want [a]", off)
+	}
+}
@@ -2,12 +2,19 @@ package experiment
 
 import (
 	"fmt"
+	"net/url"
+	"strings"
 
 	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
 	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/validation"
 )
 
 func validateSpec(s model.ExperimentSpec) error {
+	for i, k := range s.SSHKeys {
+		if _, _, err := validation.ParseSSHPublicKey(k); err != nil {
+			return fmt.Errorf("ssh_public_keys[%d]: %w", i, err)
+		}
+	}
 	names := map[string]string{}
 	for _, n := range s.Nodes {
 		if n.Name == "" {
@@ -30,14 +37,50 @@ func validateSpec(s model.ExperimentSpec) error {
 				return fmt.Errorf("xenvm %q disk_gb must be >= 1", n.Name)
 			}
 		}
+		if n.Kind == "docker" {
+			if err := validateDocker(n); err != nil {
+				return err
+			}
+		}
 		// blockstores
 		for _, b := range n.Blockstores {
 			if b.Name == "" {
 				return fmt.Errorf("node %q blockstore missing name", n.Name)
 			}
+			if b.Dataset != "" {
+				if err := validateDatasetBlockstore(n, b); err != nil {
+					return err
+				}
+				continue
+			}
 			if b.Size < 1 {
 				return fmt.Errorf("node %q blockstore %q size_gb must be >= 1", n.Name, b.Name)
 			}
+			if b.ReadOnly != nil {
+				return fmt.Errorf("node %q blockstore %q readonly requires a dataset", n.Name, b.Name)
+			}
+		}
+		if n.ComponentID != "" {
+			if n.Kind != "rawpc" {
+				return fmt.Errorf("node %q: component_id is only supported on rawpc", n.Name)
+			}
+			if err := validation.CheckComponent(n.ComponentID, n.Aggregate, n.HardwareType); err != nil {
+				return fmt.Errorf("rawpc %q: %w", n.Name, err)
+			}
+		}
+		// startup services
+		for _, e := range n.Execute {
+			if strings.TrimSpace(e.Command) == "" {
+				return fmt.Errorf("node %q execute command must not be empty", n.Name)
+			}
+		}
+		for _, in := range n.Install {
+			if u, err := url.Parse(in.URL); err != nil || u.Scheme == "" || u.Host == "" {
+				return fmt.Errorf("node %q install url %q is not a URL", n.Name, in.URL)
+			}
+			if !strings.HasPrefix(in.Path, "/") {
+				return fmt.Errorf("node %q install path %q must be absolute", n.Name, in.Path)
+			}
 		}
 		// aggregate (optional) must be in list when set
 		if n.Aggregate != "" && !validation.IsValidAggregate(n.Aggregate) {
@@ -46,18 +89,24 @@ func validateSpec(s model.ExperimentSpec) error {
 	}
 
 	for _, n := range s.Nodes {
-		if n.Kind == "xenvm" && n.InstantiateOn != "" {
+		if (n.Kind == "xenvm" || n.Kind == "docker") && n.InstantiateOn != "" {
 			if k, ok := names[n.InstantiateOn]; !ok || k != "rawpc" {
-				return fmt.Errorf("xenvm %q instantiate_on must reference an existing rawpc (got %q)", n.Name, n.InstantiateOn)
+				return fmt.Errorf("%s %q instantiate_on must reference an existing rawpc (got %q)", n.Kind, n.Name, n.InstantiateOn)
 			}
 		}
 	}
 
+	// Generated topology links share the namespace with declared ones.
+	linkNames := map[string]bool{}
 	for _, l := range s.Links {
 		if l.Name == "" {
 			return fmt.Errorf("link name is required")
 		}
-		if len(l.Interfaces) < 2 {
+		if linkNames[l.Name] {
+			return fmt.Errorf("duplicate link name: %s", l.Name)
+		}
+		linkNames[l.Name] = true
+		if len(l.Interfaces) < 2 && !isSharedVlan(l) {
 			return fmt.Errorf("%s %q must have at least 2 interfaces", l.Kind, l.Name)
 		}
 		if l.Kind == "link" && len(l.Interfaces) != 2 {
@@ -68,9 +117,21 @@ func validateSpec(s model.ExperimentSpec) error {
 				return fmt.Errorf("link %q references unknown node %q", l.Name, ifc.Node)
 			}
 		}
-		if l.Plr != nil && (*l.Plr < 0.0 || *l.Plr > 1.0) {
-			return fmt.Errorf("bridged_link %q plr must be between 0.0 and 1.0", l.Name)
+		if err := validateShaping(l); err != nil {
+			return err
 		}
+		if err := validateLinkOptions(l, names); err != nil {
+			return err
+		}
+	}
+	if err := validateStitching(s); err != nil {
+		return err
+	}
+	if err := validateSharedVlans(s.Links); err != nil {
+		return err
+	}
+	if err := validateAddressing(s); err != nil {
+		return err
 	}
 	return nil
 }
@@ -2,10 +2,14 @@ package experiment
 
 import (
 	"context"
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/retry"
 
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/model"
 	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
 )
 
@@ -34,12 +38,28 @@ func deepReady(p *portalclient.StatusPayload) bool {
 	return true
 }
 
+// wantsReady reports whether waitFor needs every node ready rather than a
+// minimum rank; "startup" waits for ready, then for startupDone.
+func wantsReady(waitFor string) bool {
+	return canon(waitFor) == StatusReady || canon(waitFor) == StatusStartup
+}
+
+// statusReached reports whether an experiment or node status has reached
+// waitFor: exactly ready when wantsReady, else at least waitFor's rank.
+func statusReached(status, waitFor string) bool {
+	if wantsReady(waitFor) {
+		return canon(status) == StatusReady
+	}
+	return rankOf(status) >= rankOf(waitFor)
+}
+
 // Predicate:
 // - waitFor == "ready"  -> require deepReady() (every node ready)
+// - waitFor == "startup" -> same as "ready"; startupDone() is checked by the caller
 // - else                -> succeed when current rank >= target rank
 func Predicate(ctx context.Context, waitFor string) func(*portalclient.StatusPayload) bool {
 	target := rankOf(waitFor)
-	wantReady := canon(waitFor) == StatusReady
+	wantReady := wantsReady(waitFor)
 
 	return func(p *portalclient.StatusPayload) bool {
 		curStatus := "<nil>"
@@ -59,6 +79,177 @@ func Predicate(ctx context.Context, waitFor string) func(*portalclient.StatusPay
 		if wantReady {
 			return deepReady(p)
 		}
-		return curRank >= target
+		return statusReached(p.Status, waitFor)
+	}
+}
+
+// startupDone reports whether every node in want has finished its startup
+// services. It errors as soon as one of them exits non-zero.
+func startupDone(st map[string]portalclient.NodeStartup, want []string) (bool, error) {
+	for _, name := range want {
+		n, ok := st[name]
+		if !ok || n.ExitCode == nil {
+			return false, nil
+		}
+		if *n.ExitCode != 0 {
+			return false, fmt.Errorf("startup services on node %q exited with code %d", name, *n.ExitCode)
+		}
+	}
+	return true, nil
+}
+
+// terminateAndWait terminates an experiment and waits until the portal no
+// longer knows it, so the name can be reused for the next attempt. Only a
+// not-found answer counts as gone; other status errors are retried.
+func terminateAndWait(ctx context.Context, cfg *portalclient.Config, project, expName string) error {
+	tflog.Info(ctx, "terminating experiment", map[string]any{"project": project, "experiment": expName})
+	if _, err := portalclient.Terminate(cfg.Client, project, expName); err != nil {
+		tflog.Warn(ctx, "terminate failed; checking status", map[string]any{"error": err})
+	}
+	tick := time.NewTicker(10 * time.Second)
+	defer tick.Stop()
+	var lastErr error
+	for {
+		resp, err := portalclient.Status(cfg.Client, project, expName, true, false, false)
+		if portalclient.IsNotFound(resp, err) {
+			return nil // gone
+		}
+		if err != nil {
+			lastErr = err
+			tflog.Warn(ctx, "status check failed while terminating; retrying", map[string]any{"experiment": expName, "error": err})
+		}
+		select {
+		case <-ctx.Done():
+			if lastErr != nil {
+				return fmt.Errorf("waiting for %q to terminate: %w (last status error: %v)", expName, ctx.Err(), lastErr)
+			}
+			return fmt.Errorf("waiting for %q to terminate: %w", expName, ctx.Err())
+		case <-tick.C:
+		}
+	}
+}
+
+// waitScheduled polls until the portal has accepted a scheduled start: the
+// experiment is scheduled/waiting, or has already begun provisioning.
+// Create returns then; the nodes come up at start_at, long after the
+// create timeout may have run out.
+func waitScheduled(ctx context.Context, cfg *portalclient.Config, project, expName string) error {
+	const accepted = "accepted"
+	to := 5 * time.Minute
+	if dl, ok := ctx.Deadline(); ok {
+		to = time.Until(dl)
+	}
+	stateConf := &retry.StateChangeConf{
+		Pending:    []string{""},
+		Target:     []string{accepted},
+		Timeout:    to,
+		Delay:      5 * time.Second,
+		MinTimeout: 5 * time.Second,
+		Refresh: func() (interface{}, string, error) {
+			resp, err := portalclient.Status(cfg.Client, project, expName, true, false, true)
+			if err != nil {
+				tflog.Warn(ctx, "status fetch failed; retrying", map[string]any{"error": err})
+				return nil, "", nil
+			}
+			p, perr := portalclient.ParseStatusJSONLoose(resp.Output)
+			if perr != nil || strings.TrimSpace(p.Status) == "" {
+				return nil, "", nil
+			}
+			switch st := canon(p.Status); {
+			case st == StatusFailed:
+				return p, st, &portalclient.FailedError{Experiment: expName, Output: resp.Output}
+			case st == StatusScheduled, st == StatusWaiting, rankOf(st) > 0:
+				return p, accepted, nil
+			}
+			return p, "", nil
+		},
+	}
+	if _, err := stateConf.WaitForStateContext(ctx); err != nil {
+		return fmt.Errorf("waiting for the portal to schedule %q: %w", expName, err)
+	}
+	return nil
+}
+
+// nodeSettle is how long waitForNodes waits to see a node go down before
+// taking "already back" at face value (a quick VM reboot can fall between
+// two polls).
+const nodeSettle = 2 * time.Minute
+
+// nodeAt is Predicate for a single node: its status has reached waitFor
+// and, as deepReady requires, a ready node has its IPv4 address.
+func nodeAt(n portalclient.StatusNode, waitFor string) bool {
+	if !statusReached(n.Status, waitFor) {
+		return false
+	}
+	return !wantsReady(waitFor) || strings.TrimSpace(n.IPv4) != ""
+}
+
+// waitForNodes polls per-node status until every node in names has gone
+// down and come back to waitFor (nodeAt), and with "startup" has finished
+// its execute services again (startupDone), by the same rules Predicate
+// applies to the whole experiment. The experiment itself stays "ready" through a
+// reload or reboot, so its status cannot tell when the nodes are back.
+func waitForNodes(ctx context.Context, cfg *portalclient.Config, project, expName string, spec model.ExperimentSpec, names []string, waitFor string) error {
+	hasExec := map[string]bool{}
+	for _, n := range spec.Nodes {
+		hasExec[n.Name] = len(n.Execute) > 0
+	}
+	start := time.Now()
+	wentDown := map[string]bool{}
+	pending := names
+
+	tick := time.NewTicker(10 * time.Second)
+	defer tick.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("waiting for nodes %v of %q to reach %q: %w", pending, expName, waitFor, ctx.Err())
+		case <-tick.C:
+		}
+		resp, err := portalclient.Status(cfg.Client, project, expName, true, false, true)
+		if err != nil {
+			tflog.Warn(ctx, "status fetch failed; retrying", map[string]any{"error": err})
+			continue
+		}
+		p, err := portalclient.ParseStatusJSONLoose(resp.Output)
+		if err != nil {
+			tflog.Warn(ctx, "bad status json; retrying", map[string]any{"error": err})
+			continue
+		}
+		if canon(p.Status) == StatusFailed {
+			return &portalclient.FailedError{Experiment: expName, Output: resp.Output}
+		}
+		var startup map[string]portalclient.NodeStartup
+		if canon(waitFor) == StatusStartup {
+			if startup, err = portalclient.ParseStartupStatus(resp.Output); err != nil {
+				tflog.Warn(ctx, "bad startup status; retrying", map[string]any{"error": err})
+				continue
+			}
+		}
+
+		nodes := portalclient.FlattenNodes(p)
+		pending = nil
+		for _, name := range names {
+			n, ok := nodes[name]
+			switch {
+			case !ok || !nodeAt(n, waitFor):
+				wentDown[name] = true
+				pending = append(pending, name)
+			case !wentDown[name] && time.Since(start) < nodeSettle:
+				pending = append(pending, name) // not seen going down yet
+			case canon(waitFor) == StatusStartup && hasExec[name]:
+				done, err := startupDone(startup, []string{name})
+				if err != nil {
+					return err
+				}
+				if !done {
+					pending = append(pending, name)
+				}
+			}
+		}
+		tflog.Debug(ctx, "node wait tick", map[string]any{"experiment": expName, "target": waitFor, "pending": pending})
+		if len(pending) == 0 {
+			return nil
+		}
 	}
 }
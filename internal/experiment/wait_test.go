@@ -0,0 +1,66 @@
+package experiment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+func TestNodeAt(t *testing.T) {
+	tests := []struct {
+		name    string
+		node    portalclient.StatusNode
+		waitFor string
+		want    bool
+	}{
+		{"ready with address", portalclient.StatusNode{Status: "ready", IPv4: "10.0.0.1"}, StatusReady, true},
+		{"ready without address", portalclient.StatusNode{Status: "ready"}, StatusReady, false},
+		{"reloading", portalclient.StatusNode{Status: "reloading", IPv4: "10.0.0.1"}, StatusReady, false},
+		{"startup needs ready", portalclient.StatusNode{Status: "booted", IPv4: "10.0.0.1"}, StatusStartup, false},
+		{"booted reaches provisioned", portalclient.StatusNode{Status: "booted"}, StatusProvisioned, true},
+		{"shutdown below provisioned", portalclient.StatusNode{Status: "shutdown"}, StatusProvisioned, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nodeAt(tt.node, tt.waitFor); got != tt.want {
+				t.Fatalf("nodeAt = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Predicate and nodeAt must agree on what a status reaching waitFor means.
+func TestNodeAtMatchesPredicate(t *testing.T) {
+	for _, waitFor := range []string{StatusProvisioned, StatusBooted, StatusReady, StatusStartup} {
+		done := Predicate(context.Background(), waitFor)
+		for _, st := range []string{StatusCreating, StatusProvisioned, StatusBooting, StatusBooted, StatusReady} {
+			n := portalclient.StatusNode{Status: st, IPv4: "10.0.0.1"}
+			p := &portalclient.StatusPayload{Status: st}
+			if got, want := nodeAt(n, waitFor), done(p); got != want {
+				t.Errorf("waitFor %s, status %s: nodeAt = %v, Predicate = %v", waitFor, st, got, want)
+			}
+		}
+	}
+}
+
+func TestStartupDone(t *testing.T) {
+	zero, one := 0, 1
+	st := map[string]portalclient.NodeStartup{
+		"a": {State: "finished", ExitCode: &zero},
+		"b": {State: "running"},
+		"c": {State: "finished", ExitCode: &one},
+	}
+	if done, err := startupDone(st, []string{"a"}); !done || err != nil {
+		t.Fatalf("a: %v, %v", done, err)
+	}
+	if done, err := startupDone(st, []string{"a", "b"}); done || err != nil {
+		t.Fatalf("a,b: %v, %v", done, err)
+	}
+	if _, err := startupDone(st, []string{"c"}); err == nil {
+		t.Fatal("c: expected non-zero exit error")
+	}
+	if done, _ := startupDone(st, []string{"missing"}); done {
+		t.Fatal("missing node reported done")
+	}
+}
@@ -0,0 +1,84 @@
+package experimentlist
+
+import (
+	"context"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+// DataSource is cloudlab_experiments.
+func DataSource() *schema.Resource {
+	return &schema.Resource{
+		ReadContext: dataSourceRead,
+		Schema: map[string]*schema.Schema{
+			"project": {Type: schema.TypeString, Optional: true},
+
+			// filters
+			"name_prefix": {Type: schema.TypeString, Optional: true},
+			"creator":     {Type: schema.TypeString, Optional: true},
+			"status":      {Type: schema.TypeString, Optional: true},
+
+			// outputs
+			"experiments": {Type: schema.TypeList, Computed: true, Elem: &schema.Resource{Schema: map[string]*schema.Schema{
+				"name":    {Type: schema.TypeString, Computed: true},
+				"uuid":    {Type: schema.TypeString, Computed: true},
+				"status":  {Type: schema.TypeString, Computed: true},
+				"creator": {Type: schema.TypeString, Computed: true},
+				"created": {Type: schema.TypeString, Computed: true},
+				"expires": {Type: schema.TypeString, Computed: true},
+			}}},
+		},
+	}
+}
+
+// Match reports whether e passes the name prefix, creator and status
+// filters; empty filters match everything.
+func Match(e portalclient.ExperimentInfo, namePrefix, creator, status string) bool {
+	if namePrefix != "" && !strings.HasPrefix(e.Name, namePrefix) {
+		return false
+	}
+	if creator != "" && !strings.EqualFold(e.Creator, creator) {
+		return false
+	}
+	if status != "" && !strings.EqualFold(strings.TrimSpace(e.Status), strings.TrimSpace(status)) {
+		return false
+	}
+	return true
+}
+
+func dataSourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	project := d.Get("project").(string)
+	if project == "" {
+		project = cfg.Project
+	}
+	prefix := d.Get("name_prefix").(string)
+	creator := d.Get("creator").(string)
+	status := d.Get("status").(string)
+
+	tflog.Debug(ctx, "listing experiments", map[string]any{"project": project})
+	all, err := portalclient.ListExperiments(cfg.Client, project)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+
+	list := []map[string]interface{}{}
+	for _, e := range all {
+		if !Match(e, prefix, creator, status) {
+			continue
+		}
+		list = append(list, map[string]interface{}{
+			"name": e.Name, "uuid": e.UUID, "status": e.Status,
+			"creator": e.Creator, "created": e.Created, "expires": e.Expires,
+		})
+	}
+	_ = d.Set("experiments", list)
+	d.SetId(strings.Join([]string{project, prefix, creator, status}, "|"))
+	return nil
+}
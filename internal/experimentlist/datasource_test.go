@@ -0,0 +1,32 @@
+package experimentlist
+
+import (
+	"testing"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+)
+
+func TestMatch(t *testing.T) {
+	e := portalclient.ExperimentInfo{Name: "tf-ci-42", Creator: "Alice", Status: "ready "}
+	tests := []struct {
+		name                    string
+		prefix, creator, status string
+		want                    bool
+	}{
+		{name: "no filters", want: true},
+		{name: "prefix", prefix: "tf-ci-", want: true},
+		{name: "prefix mismatch", prefix: "thesis"},
+		{name: "creator any case", creator: "alice", want: true},
+		{name: "creator mismatch", creator: "bob"},
+		{name: "status trimmed", status: "Ready", want: true},
+		{name: "status mismatch", status: "failed"},
+		{name: "all", prefix: "tf-", creator: "ALICE", status: "ready", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Match(e, tt.prefix, tt.creator, tt.status); got != tt.want {
+				t.Fatalf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,29 +1,58 @@
 package model
 
 type ExperimentSpec struct {
-	Nodes []Node `json:"nodes"`
-	Links []Link `json:"links,omitempty"`
+	Nodes   []Node   `json:"nodes"`
+	Links   []Link   `json:"links,omitempty"`
+	SSHKeys []string `json:"ssh_keys,omitempty"` // installed on every node
 }
 
 type Node struct {
-	Kind          string       `json:"kind"` // "rawpc" | "xenvm"
-	Name          string       `json:"name"`
-	HardwareType  string       `json:"hardware_type,omitempty"` // rawpc
-	Exclusive     *bool        `json:"exclusive,omitempty"`     // rawpc
-	Cores         *int         `json:"cores,omitempty"`         // xenvm
-	RamMB         *int         `json:"ram,omitempty"`           // MB
-	DiskGB        *int         `json:"disk,omitempty"`          // GB
-	InstantiateOn string       `json:"instantiate_on,omitempty"`
-	DiskImage     string       `json:"disk_image,omitempty"`
-	Aggregate     string       `json:"aggregate,omitempty"`   // optional
-	RoutableIP    *bool        `json:"routable_ip,omitempty"` // optional
-	Blockstores   []Blockstore `json:"blockstores,omitempty"`
+	Kind          string `json:"kind"` // "rawpc" | "xenvm" | "docker" | "remote_blockstore"
+	Name          string `json:"name"`
+	HardwareType  string `json:"hardware_type,omitempty"` // rawpc
+	ComponentID   string `json:"component_id,omitempty"`  // rawpc: pin to a physical node URN
+	Exclusive     *bool  `json:"exclusive,omitempty"`     // rawpc, docker
+	Cores         *int   `json:"cores,omitempty"`         // xenvm, docker
+	RamMB         *int   `json:"ram,omitempty"`           // MB
+	DiskGB        *int   `json:"disk,omitempty"`          // GB
+	InstantiateOn string `json:"instantiate_on,omitempty"`
+	DiskImage     string `json:"disk_image,omitempty"`
+	Aggregate     string `json:"aggregate,omitempty"` // optional
+	// Ordered fallback sites; resolved into Aggregate before encoding.
+	AggregatePreference []string     `json:"-"`
+	RoutableIP          *bool        `json:"routable_ip,omitempty"` // optional
+	Blockstores         []Blockstore `json:"blockstores,omitempty"`
+	Execute             []Execute    `json:"execute,omitempty"` // startup services
+	Install             []Install    `json:"install,omitempty"` // tarballs unpacked before execute
+
+	// Desired power state ("on" | "off"); applied after provisioning.
+	PowerState string `json:"-"`
+	// Changing any value reboots the node.
+	RebootTriggers map[string]string `json:"-"`
+
+	// docker
+	DockerImage string `json:"docker_image,omitempty"`  // registry image, e.g. "ubuntu:22.04"
+	Dockerfile  string `json:"dockerfile,omitempty"`    // URL of a Dockerfile to build
+	ExecOnStart string `json:"exec_on_start,omitempty"` // command run in the container after start
 }
 
 type Blockstore struct {
-	Name  string `json:"name"`
-	Mount string `json:"mount,omitempty"`
-	Size  int    `json:"size"` // GB
+	Name      string `json:"name"`
+	Mount     string `json:"mount,omitempty"`
+	Size      int    `json:"size,omitempty"`      // GB; unset for datasets
+	Dataset   string `json:"dataset,omitempty"`   // ltdataset/imdataset URN
+	ReadOnly  *bool  `json:"readonly,omitempty"`  // datasets only
+	Placement string `json:"placement,omitempty"` // "sysvol" | "nonsysvol" | "any"
+}
+
+type Execute struct {
+	Shell   string `json:"shell"` // "sh" | "bash"
+	Command string `json:"command"`
+}
+
+type Install struct {
+	URL  string `json:"url"`
+	Path string `json:"path"`
 }
 
 type Link struct {
@@ -33,9 +62,39 @@ type Link struct {
 	Bandwidth  *int     `json:"bandwidth,omitempty"` // Mbps
 	Latency    *int     `json:"latency,omitempty"`   // ms
 	Plr        *float64 `json:"plr,omitempty"`       // 0..1
+	Subnet     string   `json:"subnet,omitempty"`    // CIDR for automatic addressing
+
+	// Shaping semantics; DelayNode is derived at encode time.
+	EndnodeShaping *bool `json:"endnode_shaping,omitempty"`
+	DelayNode      bool  `json:"delay_node,omitempty"`
+
+	// Advanced options passed through to the profile.
+	VlanTagging      *bool  `json:"vlan_tagging,omitempty"`
+	LinkMultiplexing *bool  `json:"link_multiplexing,omitempty"`
+	BestEffort       *bool  `json:"best_effort,omitempty"`
+	TrivialOK        *bool  `json:"trivial_ok,omitempty"`
+	Protocol         string `json:"protocol,omitempty"` // "ethernet" | "infiniband"
+
+	// Cross-aggregate links: "stitched" (layer-2 VLAN stitching), "egre"
+	// (ethernet over GRE) or "gre" (IP over GRE). Empty for single-site links.
+	Stitching string `json:"stitching,omitempty"`
+
+	// Shared VLANs (lan only): create one, or attach to another experiment's.
+	SharedVlanCreate  string `json:"shared_vlan_create,omitempty"`
+	SharedVlanConnect string `json:"shared_vlan_connect,omitempty"`
 }
 
 type Iface struct {
 	Node   string `json:"node"`
 	IfName string `json:"ifname,omitempty"`
+
+	// Experiment-network address; filled from the link subnet when unset.
+	IP      string `json:"ip,omitempty"`
+	Netmask string `json:"netmask,omitempty"` // dotted quad
+
+	// Per-direction shaping for traffic leaving this interface; overrides
+	// the link-level values for that direction.
+	Bandwidth *int     `json:"bandwidth,omitempty"` // Mbps
+	Latency   *int     `json:"latency,omitempty"`   // ms
+	Plr       *float64 `json:"plr,omitempty"`       // 0..1
 }
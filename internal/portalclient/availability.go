@@ -0,0 +1,71 @@
+package portalclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// Availability is the free/total count of one hardware type on one cluster.
+// Reserved counts free nodes already promised to other reservations.
+type Availability struct {
+	Aggregate    string
+	HardwareType string
+	Free         int
+	Total        int
+	Reserved     int
+}
+
+type availabilityCounts struct {
+	Free     int `json:"free"`
+	Total    int `json:"total"`
+	Reserved int `json:"reserved"`
+}
+
+// ParseAvailability decodes resourceAvailability output, a JSON object of
+// aggregate URN -> hardware type -> counts, into a list sorted by aggregate
+// and type.
+func ParseAvailability(s string) ([]Availability, error) {
+	var raw map[string]map[string]availabilityCounts
+	err := decodeLoose(s, func(b []byte) error {
+		raw = nil
+		return json.Unmarshal(b, &raw)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no decodable availability JSON in portal output (len=%d)", len(s))
+	}
+
+	var out []Availability
+	for agg, types := range raw {
+		for typ, c := range types {
+			out = append(out, Availability{
+				Aggregate: agg, HardwareType: typ,
+				Free: c.Free, Total: c.Total, Reserved: c.Reserved,
+			})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Aggregate != out[j].Aggregate {
+			return out[i].Aggregate < out[j].Aggregate
+		}
+		return out[i].HardwareType < out[j].HardwareType
+	})
+	return out, nil
+}
+
+// ResourceAvailability invokes portal.resourceAvailability. Empty aggregate
+// or hardwareType means all.
+func ResourceAvailability(c *Client, aggregate, hardwareType string) ([]Availability, error) {
+	params := map[string]any{}
+	if aggregate != "" {
+		params["cluster"] = aggregate
+	}
+	if hardwareType != "" {
+		params["type"] = hardwareType
+	}
+	resp, err := call(c, "resourceAvailability", params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAvailability(resp.Output)
+}
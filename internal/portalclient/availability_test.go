@@ -0,0 +1,86 @@
+package portalclient
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readFixture(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+const (
+	utahURN    = "urn:publicid:IDN+utah.cloudlab.us+authority+cm"
+	clemsonURN = "urn:publicid:IDN+clemson.cloudlab.us+authority+cm"
+)
+
+func TestParseAvailability(t *testing.T) {
+	tests := []struct {
+		fixture string
+		want    []Availability
+	}{
+		{
+			// Every cluster; wisc reports no types, and c6420 only exists on clemson.
+			fixture: "availability_all.txt",
+			want: []Availability{
+				{Aggregate: clemsonURN, HardwareType: "c6420", Free: 7, Total: 72, Reserved: 3},
+				{Aggregate: utahURN, HardwareType: "m510", Free: 41, Total: 270, Reserved: 12},
+				{Aggregate: utahURN, HardwareType: "xl170", Free: 0, Total: 200, Reserved: 0},
+			},
+		},
+		{
+			// Filtered to one type; reserved omitted by the portal.
+			fixture: "availability_type.txt",
+			want: []Availability{
+				{Aggregate: clemsonURN, HardwareType: "c6420", Free: 7, Total: 72},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			got, err := ParseAvailability(readFixture(t, tt.fixture))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v\nwant %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseAvailabilityTypeAbsent(t *testing.T) {
+	got, err := ParseAvailability(readFixture(t, "availability_all.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, a := range got {
+		if a.Aggregate == clemsonURN && a.HardwareType == "m510" {
+			t.Fatalf("m510 is not on clemson but got %+v", a)
+		}
+		if a.Aggregate == "urn:publicid:IDN+wisc.cloudlab.us+authority+cm" {
+			t.Fatalf("wisc reports no types but got %+v", a)
+		}
+	}
+}
+
+func TestParseAvailabilityMalformed(t *testing.T) {
+	for _, fixture := range []string{
+		"availability_truncated.txt",
+		"availability_badcounts.txt",
+		"availability_error.txt",
+	} {
+		t.Run(fixture, func(t *testing.T) {
+			if got, err := ParseAvailability(readFixture(t, fixture)); err == nil {
+				t.Fatalf("expected error, got %+v", got)
+			}
+		})
+	}
+}
@@ -10,13 +10,20 @@ import (
 )
 
 // Re-export types for provider packages.
-type Client = portal.Client
 type StatusPayload = portal.StatusPayload
 type EmulabResponse = portal.EmulabResponse
+type StatusNode = portal.StatusNode
+
+// Client is portalctl's client plus a plain XML-RPC endpoint for the portal
+// methods portalctl has no wrapper for (see call).
+type Client struct {
+	*portal.Client
+	rpc *rpcClient
+}
 
 // New returns a configured XML-RPC client.
-func New(o Options) (*portal.Client, error) {
-	return portal.New(portal.Options{
+func New(o Options) (*Client, error) {
+	c, err := portal.New(portal.Options{
 		Server:  o.Server,
 		Port:    o.Port,
 		Path:    o.Path,
@@ -25,34 +32,45 @@ func New(o Options) (*portal.Client, error) {
 		Verify:  o.Verify,
 		Timeout: o.Timeout,
 	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Client: c, rpc: newRPCClient(o)}, nil
 }
 
 // ----- High-level helpers (provider-friendly) -----
 // StartExperiment is pass-through; params already contain project/name.
-func StartExperiment(c *portal.Client, params map[string]any) (*portal.EmulabResponse, error) {
+func StartExperiment(c *Client, params map[string]any) (*portal.EmulabResponse, error) {
 	return c.StartExperiment(params)
 }
 
 // Status calls portal.experimentStatus with "project,exp" combined into
 // the XML-RPC "experiment" parameter, since the backend accepts comma form.
-func Status(c *portal.Client, project, exp string, asJSON, withCert, refresh bool) (*portal.EmulabResponse, error) {
+func Status(c *Client, project, exp string, asJSON, withCert, refresh bool) (*portal.EmulabResponse, error) {
 	combined := fmt.Sprintf("%s,%s", strings.TrimSpace(project), strings.TrimSpace(exp))
 	return c.ExperimentStatus(combined, asJSON, withCert, refresh)
 }
 
 // Terminate invokes portal.terminateExperiment with "project,exp".
-func Terminate(c *portal.Client, project, exp string) (*portal.EmulabResponse, error) {
+func Terminate(c *Client, project, exp string) (*portal.EmulabResponse, error) {
 	combined := fmt.Sprintf("%s,%s", strings.TrimSpace(project), strings.TrimSpace(exp))
 	return c.TerminateExperiment(combined)
 }
 
 // Manifests invokes portal.experimentManifests with "project,exp".
-func Manifests(c *portal.Client, project, exp string) (*portal.EmulabResponse, error) {
+func Manifests(c *Client, project, exp string) (*portal.EmulabResponse, error) {
 	combined := fmt.Sprintf("%s,%s", strings.TrimSpace(project), strings.TrimSpace(exp))
 	return c.ExperimentManifests(combined)
 }
 
-func Wait(ctx context.Context, c *portal.Client, exp string, interval, timeout time.Duration, done func(*portal.StatusPayload) bool) (*portal.StatusPayload, error) {
+// call invokes any portal.* XML-RPC method with a struct of parameters; the
+// helpers for methods portalctl has no wrapper for go through it. It only
+// needs portalctl's connection options, not a newer portalctl.
+func call(c *Client, method string, params map[string]any) (*portal.EmulabResponse, error) {
+	return c.rpc.call(method, params)
+}
+
+func Wait(ctx context.Context, c *Client, exp string, interval, timeout time.Duration, done func(*portal.StatusPayload) bool) (*portal.StatusPayload, error) {
 	return c.WaitForStatus(ctx, exp, interval, timeout, done)
 }
 
@@ -0,0 +1,101 @@
+package portalclient
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	portal "github.com/csc478-wcu/portalctl/portal"
+)
+
+// ResponseSearchFailed is the Emulab XML-RPC code for a lookup that found
+// nothing, e.g. experimentStatus on a terminated experiment.
+const ResponseSearchFailed = 12
+
+// Phrases the portal uses when an experiment does not exist.
+var notFoundPhrases = []string{
+	"no such experiment",
+	"does not exist",
+	"not found",
+}
+
+// ResponseError is a portal method that answered with a non-zero code.
+type ResponseError struct {
+	Method string
+	Code   int
+	Output string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("portal.%s: code %d: %s", e.Method, e.Code, strings.TrimSpace(e.Output))
+}
+
+// IsNotFound reports whether a portal call failed because the experiment
+// (or other object) does not exist, as opposed to a transport or server
+// error. resp may be nil when the caller only kept the error.
+func IsNotFound(resp *portal.EmulabResponse, err error) bool {
+	if resp != nil && resp.Code == ResponseSearchFailed {
+		return true
+	}
+	var re *ResponseError
+	if errors.As(err, &re) && re.Code == ResponseSearchFailed {
+		return true
+	}
+	var msg string
+	if err != nil {
+		msg = err.Error()
+	}
+	if resp != nil && resp.Code != 0 {
+		msg += " " + resp.Output
+	}
+	msg = strings.ToLower(msg)
+	for _, p := range notFoundPhrases {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// FailedError reports an experiment that reached the "failed" state while
+// we were waiting on it. Output is the raw status text.
+type FailedError struct {
+	Experiment string
+	Output     string
+}
+
+func (e *FailedError) Error() string {
+	return fmt.Sprintf("experiment %q failed: %s", e.Experiment, strings.TrimSpace(e.Output))
+}
+
+// Phrases the portal and the cluster mappers use when they cannot find free
+// hardware for a request.
+var insufficientPhrases = []string{
+	"insufficient",
+	"not enough",
+	"no available",
+	"could not map",
+	"unable to map",
+	"resource reservation violation",
+	"no free nodes",
+}
+
+// IsInsufficientResources reports whether err (a StartExperiment error or a
+// *FailedError) says the request could not be satisfied with free hardware.
+func IsInsufficientResources(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	var fe *FailedError
+	if errors.As(err, &fe) {
+		msg += " " + fe.Output
+	}
+	msg = strings.ToLower(msg)
+	for _, p := range insufficientPhrases {
+		if strings.Contains(msg, p) {
+			return true
+		}
+	}
+	return false
+}
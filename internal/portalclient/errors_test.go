@@ -0,0 +1,52 @@
+package portalclient
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	portal "github.com/csc478-wcu/portalctl/portal"
+)
+
+func TestIsNotFound(t *testing.T) {
+	tests := []struct {
+		name string
+		resp *portal.EmulabResponse
+		err  error
+		want bool
+	}{
+		{name: "search failed code", resp: &portal.EmulabResponse{Code: ResponseSearchFailed}, err: errors.New("rpc error"), want: true},
+		{name: "no such experiment", err: errors.New("No such experiment: proj,exp"), want: true},
+		{name: "output says missing", resp: &portal.EmulabResponse{Code: 2, Output: "Experiment does not exist"}, want: true},
+		{name: "wrapped search failed", err: fmt.Errorf("reading: %w", &ResponseError{Method: "reservationInfo", Code: ResponseSearchFailed}), want: true},
+		{name: "other response code", err: &ResponseError{Method: "imageInfo", Code: 5, Output: "internal error"}},
+		{name: "transport error", err: errors.New("dial tcp: i/o timeout")},
+		{name: "server error", resp: &portal.EmulabResponse{Code: 5, Output: "internal error"}, err: errors.New("server error")},
+		{name: "success", resp: &portal.EmulabResponse{Code: 0, Output: `{"status":"ready"}`}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.resp, tt.err); got != tt.want {
+				t.Fatalf("IsNotFound = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsInsufficientResources(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{err: nil},
+		{err: errors.New("Insufficient free nodes of type d430"), want: true},
+		{err: &FailedError{Experiment: "e", Output: "*** Could not map to physical resources"}, want: true},
+		{err: &FailedError{Experiment: "e", Output: "startup script failed"}},
+		{err: errors.New("permission denied")},
+	}
+	for _, tt := range tests {
+		if got := IsInsufficientResources(tt.err); got != tt.want {
+			t.Errorf("IsInsufficientResources(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
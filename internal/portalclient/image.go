@@ -0,0 +1,126 @@
+package portalclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	portal "github.com/csc478-wcu/portalctl/portal"
+)
+
+// Image is one version of a disk image.
+type Image struct {
+	Name       string `json:"name"`
+	Project    string `json:"project"`
+	URN        string `json:"urn"`         // image URN, no version
+	Version    int    `json:"version"`     // latest or requested version
+	VersionURN string `json:"version_urn"` // URN pinned to Version
+	Status     string `json:"status"`      // "ready", "imaging", "failed", ...
+	Created    string `json:"created"`
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+}
+
+// ParseImage decodes the image object in portal output.
+func ParseImage(s string) (*Image, error) {
+	var out *Image
+	err := decodeLoose(s, func(b []byte) error {
+		var im Image
+		if err := json.Unmarshal(b, &im); err != nil {
+			return err
+		}
+		if im.URN == "" {
+			return fmt.Errorf("no urn")
+		}
+		out = &im
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no image found in portal output (len=%d)", len(s))
+	}
+	return out, nil
+}
+
+// ImageRequest describes a snapshot of one experiment node.
+type ImageRequest struct {
+	Project    string // project owning the experiment
+	Experiment string
+	Node       string // client id
+	ImageName  string
+	ImageProj  string // project to own the image; defaults to Project
+	Global     bool   // visible to everyone
+	Shared     bool   // usable by every project member's experiments
+}
+
+// CreateImage invokes portal.createImage, snapshotting a node into a new
+// image (or a new version of an existing one).
+func CreateImage(c *Client, r ImageRequest) (*Image, error) {
+	params := map[string]any{
+		"experiment": fmt.Sprintf("%s,%s", strings.TrimSpace(r.Project), strings.TrimSpace(r.Experiment)),
+		"node":       r.Node,
+		"imagename":  r.ImageName,
+		"global":     r.Global,
+		"shared":     r.Shared,
+	}
+	if r.ImageProj != "" {
+		params["project"] = r.ImageProj
+	}
+	resp, err := call(c, "createImage", params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseImage(resp.Output)
+}
+
+// ImageInfo invokes portal.imageInfo for an image URN (versioned or not).
+func ImageInfo(c *Client, urn string) (*Image, error) {
+	resp, err := call(c, "imageInfo", map[string]any{"image": urn})
+	if err != nil {
+		return nil, err
+	}
+	return ParseImage(resp.Output)
+}
+
+// DeleteImage invokes portal.deleteImage for an image URN. An unversioned
+// URN deletes every version; a version URN only that version.
+func DeleteImage(c *Client, urn string) (*portal.EmulabResponse, error) {
+	return call(c, "deleteImage", map[string]any{"image": urn})
+}
+
+// ParseImageList decodes a JSON array (or uuid/urn-keyed object) of image
+// versions.
+func ParseImageList(s string) ([]Image, error) {
+	var out []Image
+	err := decodeLoose(s, func(b []byte) error {
+		out = nil
+		if err := json.Unmarshal(b, &out); err == nil {
+			return nil
+		}
+		var byKey map[string]Image
+		if err := json.Unmarshal(b, &byKey); err != nil {
+			return err
+		}
+		for _, im := range byKey {
+			out = append(out, im)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no decodable image list in portal output (len=%d)", len(s))
+	}
+	return out, nil
+}
+
+// ListImages invokes portal.listImages; every version of each matching image
+// is returned. An empty name lists all of the project's images.
+func ListImages(c *Client, project, name string) ([]Image, error) {
+	params := map[string]any{"project": strings.TrimSpace(project)}
+	if name != "" {
+		params["imagename"] = name
+	}
+	resp, err := call(c, "listImages", params)
+	if err != nil {
+		return nil, err
+	}
+	return ParseImageList(resp.Output)
+}
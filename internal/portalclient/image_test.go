@@ -0,0 +1,28 @@
+package portalclient
+
+import "testing"
+
+func TestParseImageList(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    int
+		wantErr bool
+	}{
+		{name: "array", in: `[{"name":"a","version":0},{"name":"a","version":1}]`, want: 2},
+		{name: "keyed", in: `{"u1":{"name":"a","version":0}}`, want: 1},
+		{name: "banner then array", in: "Images in project:\n[{\"name\":\"a\",\"version\":1,\"status\":\"ready\"}]", want: 1},
+		{name: "no json", in: "no images", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseImageList(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != tt.want {
+				t.Fatalf("got %d images, want %d: %+v", len(got), tt.want, got)
+			}
+		})
+	}
+}
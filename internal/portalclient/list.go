@@ -0,0 +1,56 @@
+package portalclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ExperimentInfo is one row of an experiment listing.
+type ExperimentInfo struct {
+	Name    string `json:"name"`
+	UUID    string `json:"uuid"`
+	Project string `json:"project"`
+	Status  string `json:"status"`
+	Creator string `json:"creator"`
+	Created string `json:"created"`
+	Expires string `json:"expires"`
+}
+
+// ParseExperimentList accepts either a JSON array of experiments or an
+// object keyed by uuid, and returns them sorted by name.
+func ParseExperimentList(s string) ([]ExperimentInfo, error) {
+	var out []ExperimentInfo
+	err := decodeLoose(s, func(b []byte) error {
+		out = nil
+		if err := json.Unmarshal(b, &out); err == nil {
+			return nil
+		}
+		var byID map[string]ExperimentInfo
+		if err := json.Unmarshal(b, &byID); err != nil {
+			return err
+		}
+		for id, e := range byID {
+			if e.UUID == "" {
+				e.UUID = id
+			}
+			out = append(out, e)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no decodable experiment list in portal output (len=%d)", len(s))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+// ListExperiments invokes portal.experimentList for a project.
+func ListExperiments(c *Client, project string) ([]ExperimentInfo, error) {
+	resp, err := call(c, "experimentList", map[string]any{"proj": strings.TrimSpace(project)})
+	if err != nil {
+		return nil, err
+	}
+	return ParseExperimentList(resp.Output)
+}
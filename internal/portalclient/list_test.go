@@ -0,0 +1,38 @@
+package portalclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseExperimentList(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{name: "array", in: `[{"name":"b"},{"name":"a"}]`, want: []string{"a", "b"}},
+		{name: "keyed by uuid", in: `{"u1":{"name":"a"},"u2":{"name":"b"}}`, want: []string{"a", "b"}},
+		{name: "banner then array", in: "Experiments:\n[{\"name\":\"tf-1\",\"status\":\"ready\"}]\n", want: []string{"tf-1"}},
+		{name: "bracketed banner then array", in: "[info] 1 experiment\n[{\"name\":\"tf-1\"}]", want: []string{"tf-1"}},
+		{name: "banner then object", in: "Experiments:\n{\"u1\":{\"name\":\"a\"}}", want: []string{"a"}},
+		{name: "empty array", in: "[]", want: nil},
+		{name: "no json", in: "permission denied", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseExperimentList(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			var names []string
+			for _, e := range got {
+				names = append(names, e.Name)
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Fatalf("names = %v, want %v", names, tt.want)
+			}
+		})
+	}
+}
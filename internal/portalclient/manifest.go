@@ -0,0 +1,73 @@
+package portalclient
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+type manifestRspec struct {
+	Nodes []ManifestNode `xml:"node"`
+}
+
+// ManifestNode is one allocated node of a manifest rspec.
+type ManifestNode struct {
+	ClientID     string `xml:"client_id,attr"`
+	ComponentID  string `xml:"component_id,attr"`
+	Aggregate    string `xml:"component_manager_id,attr"`
+	HardwareType struct {
+		Name string `xml:"name,attr"`
+	} `xml:"hardware_type"`
+	SliverType struct {
+		Name string `xml:"name,attr"` // "raw-pc", "emulab-xen", "emulab-docker"
+	} `xml:"sliver_type"`
+	Logins []Login `xml:"services>login"`
+}
+
+// Login is an ssh endpoint the manifest advertises for a node, one per
+// experiment member.
+type Login struct {
+	Hostname string `xml:"hostname,attr"`
+	Port     int    `xml:"port,attr"`
+	Username string `xml:"username,attr"`
+}
+
+// ManifestComponents maps node client_id -> allocated component URN.
+func ManifestComponents(nodes []ManifestNode) map[string]string {
+	out := map[string]string{}
+	for _, n := range nodes {
+		if n.ClientID != "" && n.ComponentID != "" {
+			out[n.ClientID] = n.ComponentID
+		}
+	}
+	return out
+}
+
+// ParseManifestNodes returns every node of every manifest in
+// experimentManifests output: a JSON object of aggregate URN -> manifest
+// rspec, or a bare rspec.
+func ParseManifestNodes(s string) ([]ManifestNode, error) {
+	var byAgg map[string]string
+	if err := decodeLoose(s, func(b []byte) error {
+		byAgg = nil
+		return json.Unmarshal(b, &byAgg)
+	}); err != nil {
+		byAgg = map[string]string{"": s}
+	}
+
+	var out []ManifestNode
+	for agg, rspec := range byAgg {
+		var m manifestRspec
+		if err := xml.Unmarshal([]byte(strings.TrimSpace(rspec)), &m); err != nil {
+			return nil, fmt.Errorf("manifest for %q: %w", agg, err)
+		}
+		for _, n := range m.Nodes {
+			if n.Aggregate == "" {
+				n.Aggregate = agg
+			}
+			out = append(out, n)
+		}
+	}
+	return out, nil
+}
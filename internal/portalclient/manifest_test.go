@@ -0,0 +1,37 @@
+package portalclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseManifestNodes(t *testing.T) {
+	byAgg := `{"urn:publicid:IDN+utah.cloudlab.us+authority+cm": "<rspec><node client_id=\"n0\" component_id=\"urn:publicid:IDN+utah.cloudlab.us+node+pc1\"><hardware_type name=\"m510\"/><services><login hostname=\"pc1.utah.cloudlab.us\" port=\"22\" username=\"alice\"/></services></node><node client_id=\"n1\"/></rspec>"}`
+	nodes, err := ParseManifestNodes(byAgg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("got %d nodes", len(nodes))
+	}
+	n := nodes[0]
+	if n.ClientID != "n0" || n.HardwareType.Name != "m510" || n.Aggregate != "urn:publicid:IDN+utah.cloudlab.us+authority+cm" {
+		t.Fatalf("node = %+v", n)
+	}
+	if want := []Login{{Hostname: "pc1.utah.cloudlab.us", Port: 22, Username: "alice"}}; !reflect.DeepEqual(n.Logins, want) {
+		t.Fatalf("logins = %+v", n.Logins)
+	}
+	if got := ManifestComponents(nodes); !reflect.DeepEqual(got, map[string]string{"n0": "urn:publicid:IDN+utah.cloudlab.us+node+pc1"}) {
+		t.Fatalf("components = %v", got)
+	}
+
+	bare := `<rspec><node client_id="a" component_manager_id="urn:publicid:IDN+emulab.net+authority+cm"/></rspec>`
+	nodes, err = ParseManifestNodes(bare)
+	if err != nil || len(nodes) != 1 || nodes[0].Aggregate != "urn:publicid:IDN+emulab.net+authority+cm" {
+		t.Fatalf("bare: %+v, %v", nodes, err)
+	}
+
+	if _, err := ParseManifestNodes(`{"agg": "<rspec><node"}`); err == nil {
+		t.Fatal("expected error for truncated rspec")
+	}
+}
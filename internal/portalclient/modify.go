@@ -0,0 +1,18 @@
+package portalclient
+
+import (
+	"fmt"
+	"strings"
+
+	portal "github.com/csc478-wcu/portalctl/portal"
+)
+
+// Modify invokes portal.modifyExperiment on "project,exp" with new profile
+// bindings (a JSON object as string, like startExperiment). The portal adds
+// and removes nodes and links to match; nodes it keeps are not touched.
+func Modify(c *Client, project, exp, bindings string) (*portal.EmulabResponse, error) {
+	return call(c, "modifyExperiment", map[string]any{
+		"experiment": fmt.Sprintf("%s,%s", strings.TrimSpace(project), strings.TrimSpace(exp)),
+		"bindings":   bindings,
+	})
+}
@@ -0,0 +1,40 @@
+package portalclient
+
+import (
+	"fmt"
+	"strings"
+
+	portal "github.com/csc478-wcu/portalctl/portal"
+)
+
+func nodeParams(project, exp string, nodes []string) map[string]any {
+	return map[string]any{
+		"experiment": fmt.Sprintf("%s,%s", strings.TrimSpace(project), strings.TrimSpace(exp)),
+		"nodes":      strings.Join(nodes, ","),
+	}
+}
+
+// Reload invokes portal.reload on some nodes of "project,exp". A non-empty
+// image reloads them with that disk image instead of their current one.
+func Reload(c *Client, project, exp string, nodes []string, image string) (*portal.EmulabResponse, error) {
+	params := nodeParams(project, exp, nodes)
+	if image != "" {
+		params["image"] = image
+	}
+	return call(c, "reload", params)
+}
+
+// Power invokes portal.powercycle to turn some nodes of "project,exp" on or off.
+func Power(c *Client, project, exp string, nodes []string, on bool) (*portal.EmulabResponse, error) {
+	params := nodeParams(project, exp, nodes)
+	params["action"] = "off"
+	if on {
+		params["action"] = "on"
+	}
+	return call(c, "powercycle", params)
+}
+
+// Reboot invokes portal.reboot on some nodes of "project,exp".
+func Reboot(c *Client, project, exp string, nodes []string) (*portal.EmulabResponse, error) {
+	return call(c, "reboot", nodeParams(project, exp, nodes))
+}
@@ -2,6 +2,15 @@ package portalclient
 
 import "time"
 
+// Connection defaults shared by the provider and the command-line tools.
+const (
+	DefaultPemPath = "~/cloudlab.pem"
+	DefaultServer  = "boss.emulab.net"
+	DefaultPort    = 3069
+	DefaultPath    = "/usr/testbed"
+	DefaultTimeout = "10m"
+)
+
 type Options struct {
 	Server  string
 	Port    int
@@ -17,3 +26,37 @@ type Config struct {
 	Project string
 	PemPath string
 }
+
+// Settings are the user-facing connection settings (provider block or
+// command-line flags).
+type Settings struct {
+	Project string
+	PemPath string
+	Server  string
+	Port    int
+	Path    string
+	Timeout string // duration, e.g. "10m"
+}
+
+// Configure builds a client from s. The PEM holds both certificate and key,
+// and the portal's certificate is always self-signed.
+func Configure(s Settings) (*Config, error) {
+	to, _ := time.ParseDuration(s.Timeout)
+	cli, err := New(Options{
+		Server:  s.Server,
+		Port:    s.Port,
+		Path:    s.Path,
+		CertPEM: s.PemPath,
+		KeyPEM:  s.PemPath,
+		Verify:  false, // always self-signed
+		Timeout: to,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Config{
+		Client:  cli,
+		Project: s.Project,
+		PemPath: s.PemPath,
+	}, nil
+}
@@ -10,13 +10,30 @@ import (
 // ParseStatusJSONLoose extracts and decodes the first top-level JSON object
 // from s, tolerating non-JSON text before/after. We use this for reads only.
 func ParseStatusJSONLoose(s string) (*portal.StatusPayload, error) {
+	var out *portal.StatusPayload
+	err := decodeLoose(s, func(b []byte) error {
+		var p portal.StatusPayload
+		if err := json.Unmarshal(b, &p); err != nil {
+			return err
+		}
+		out = &p
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no decodable JSON object found in status output (len=%d)", len(s))
+	}
+	return out, nil
+}
+
+// decodeLoose calls decode on s, then on each top-level JSON object or array
+// in s, until one succeeds.
+func decodeLoose(s string, decode func([]byte) error) error {
 	// Try strict first.
-	var fast portal.StatusPayload
-	if err := json.Unmarshal([]byte(s), &fast); err == nil {
-		return &fast, nil
+	if err := decode([]byte(s)); err == nil {
+		return nil
 	}
 
-	// Scan for a JSON object and decode the first one that works.
+	// Scan for a JSON object or array and decode the first one that works.
 	start, depth := -1, 0
 	inStr, esc := false, false
 	for i := 0; i < len(s); i++ {
@@ -34,23 +51,22 @@ func ParseStatusJSONLoose(s string) (*portal.StatusPayload, error) {
 		switch c {
 		case '"':
 			inStr = true
-		case '{':
+		case '{', '[':
 			if depth == 0 {
 				start = i
 			}
 			depth++
-		case '}':
+		case '}', ']':
 			if depth > 0 {
 				depth--
 				if depth == 0 && start >= 0 {
-					var out portal.StatusPayload
-					if err := json.Unmarshal([]byte(s[start:i+1]), &out); err == nil {
-						return &out, nil
+					if err := decode([]byte(s[start : i+1])); err == nil {
+						return nil
 					}
-					start = -1 // keep scanning (there might be another object)
+					start = -1 // keep scanning (there might be another value)
 				}
 			}
 		}
 	}
-	return nil, fmt.Errorf("no decodable JSON object found in status output (len=%d)", len(s))
+	return fmt.Errorf("no decodable JSON value found")
 }
@@ -0,0 +1,94 @@
+package portalclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	portal "github.com/csc478-wcu/portalctl/portal"
+)
+
+// Reservation is an advance hardware reservation on one cluster.
+type Reservation struct {
+	UUID         string `json:"uuid"`
+	Project      string `json:"project"`
+	Aggregate    string `json:"cluster"` // aggregate URN
+	HardwareType string `json:"type"`
+	Count        int    `json:"count"`
+	Start        string `json:"start"` // RFC3339
+	End          string `json:"end"`   // RFC3339
+	Reason       string `json:"reason"`
+	Status       string `json:"status"` // e.g. "pending", "approved"
+}
+
+func (r Reservation) params() map[string]any {
+	p := map[string]any{
+		"proj":    strings.TrimSpace(r.Project),
+		"cluster": r.Aggregate,
+		"type":    r.HardwareType,
+		"count":   r.Count,
+		"start":   r.Start,
+		"end":     r.End,
+		"reason":  r.Reason,
+	}
+	if r.UUID != "" {
+		p["uuid"] = r.UUID
+	}
+	return p
+}
+
+// ParseReservation decodes the reservation object in portal output.
+func ParseReservation(s string) (*Reservation, error) {
+	var out *Reservation
+	err := decodeLoose(s, func(b []byte) error {
+		var r Reservation
+		if err := json.Unmarshal(b, &r); err != nil {
+			return err
+		}
+		if r.UUID == "" {
+			return fmt.Errorf("no uuid")
+		}
+		out = &r
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no reservation found in portal output (len=%d)", len(s))
+	}
+	return out, nil
+}
+
+// CreateReservation invokes portal.reserve and returns the stored reservation.
+func CreateReservation(c *Client, r Reservation) (*Reservation, error) {
+	resp, err := call(c, "reserve", r.params())
+	if err != nil {
+		return nil, err
+	}
+	return ParseReservation(resp.Output)
+}
+
+// GetReservation invokes portal.reservationInfo.
+func GetReservation(c *Client, project, aggregate, uuid string) (*Reservation, error) {
+	resp, err := call(c, "reservationInfo", map[string]any{
+		"proj": strings.TrimSpace(project), "cluster": aggregate, "uuid": uuid,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ParseReservation(resp.Output)
+}
+
+// UpdateReservation invokes portal.modifyReservation; UUID must be set.
+func UpdateReservation(c *Client, r Reservation) (*Reservation, error) {
+	resp, err := call(c, "modifyReservation", r.params())
+	if err != nil {
+		return nil, err
+	}
+	return ParseReservation(resp.Output)
+}
+
+// DeleteReservation invokes portal.deleteReservation.
+func DeleteReservation(c *Client, project, aggregate, uuid string) (*portal.EmulabResponse, error) {
+	return call(c, "deleteReservation", map[string]any{
+		"proj": strings.TrimSpace(project), "cluster": aggregate, "uuid": uuid,
+	})
+}
@@ -0,0 +1,309 @@
+package portalclient
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	portal "github.com/csc478-wcu/portalctl/portal"
+)
+
+// rpcVersion is the API version every portal.* call passes first.
+const rpcVersion = 0.1
+
+// rpcClient speaks Emulab XML-RPC directly for the portal methods portalctl
+// has no wrapper for. The client certificate is loaded on first use, so a
+// provider that only starts and stops experiments never needs it.
+type rpcClient struct {
+	url      string
+	certPEM  string
+	keyPEM   string
+	verify   bool
+	timeout  time.Duration
+	once     sync.Once
+	http     *http.Client
+	setupErr error
+}
+
+func newRPCClient(o Options) *rpcClient {
+	path := "/" + strings.TrimPrefix(o.Path, "/")
+	return &rpcClient{
+		url:     fmt.Sprintf("https://%s:%d%s", o.Server, o.Port, path),
+		certPEM: o.CertPEM,
+		keyPEM:  o.KeyPEM,
+		verify:  o.Verify,
+		timeout: o.Timeout,
+	}
+}
+
+func (r *rpcClient) setup() error {
+	r.once.Do(func() {
+		if r.http != nil {
+			return // preset, e.g. by tests
+		}
+		cert, err := tls.LoadX509KeyPair(expandHome(r.certPEM), expandHome(r.keyPEM))
+		if err != nil {
+			r.setupErr = fmt.Errorf("loading portal certificate: %w", err)
+			return
+		}
+		r.http = &http.Client{
+			Timeout: r.timeout,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{
+				Certificates:       []tls.Certificate{cert},
+				InsecureSkipVerify: !r.verify, // the portal's certificate is self-signed
+			}},
+		}
+	})
+	return r.setupErr
+}
+
+// call invokes portal.<method>(rpcVersion, params). A non-zero response code
+// is returned as a *ResponseError together with the response.
+func (r *rpcClient) call(method string, params map[string]any) (*portal.EmulabResponse, error) {
+	if err := r.setup(); err != nil {
+		return nil, err
+	}
+	body, err := encodeCall("portal."+method, rpcVersion, params)
+	if err != nil {
+		return nil, err
+	}
+	httpResp, err := r.http.Post(r.url, "text/xml", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("portal.%s: %w", method, err)
+	}
+	defer httpResp.Body.Close()
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("portal.%s: HTTP %s", method, httpResp.Status)
+	}
+	v, err := decodeResponse(httpResp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("portal.%s: %w", method, err)
+	}
+	resp := emulabResponse(v)
+	if resp.Code != 0 {
+		return resp, &ResponseError{Method: method, Code: resp.Code, Output: resp.Output}
+	}
+	return resp, nil
+}
+
+// emulabResponse reads the {code, value, output} struct every Emulab method
+// returns.
+func emulabResponse(v any) *portal.EmulabResponse {
+	m, _ := v.(map[string]any)
+	resp := &portal.EmulabResponse{Value: m["value"]}
+	resp.Code, _ = m["code"].(int)
+	resp.Output, _ = m["output"].(string)
+	return resp
+}
+
+func expandHome(p string) string {
+	if rest, ok := strings.CutPrefix(p, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return p
+}
+
+// ----- XML-RPC encoding -----
+
+func encodeCall(method string, args ...any) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0"?><methodCall><methodName>`)
+	xml.EscapeText(&b, []byte(method))
+	b.WriteString(`</methodName><params>`)
+	for _, a := range args {
+		b.WriteString(`<param>`)
+		if err := encodeValue(&b, a); err != nil {
+			return nil, err
+		}
+		b.WriteString(`</param>`)
+	}
+	b.WriteString(`</params></methodCall>`)
+	return b.Bytes(), nil
+}
+
+func encodeValue(b *bytes.Buffer, v any) error {
+	b.WriteString(`<value>`)
+	switch x := v.(type) {
+	case string:
+		b.WriteString(`<string>`)
+		xml.EscapeText(b, []byte(x))
+		b.WriteString(`</string>`)
+	case bool:
+		if x {
+			b.WriteString(`<boolean>1</boolean>`)
+		} else {
+			b.WriteString(`<boolean>0</boolean>`)
+		}
+	case int:
+		fmt.Fprintf(b, `<int>%d</int>`, x)
+	case int64:
+		fmt.Fprintf(b, `<int>%d</int>`, x)
+	case float64:
+		fmt.Fprintf(b, `<double>%s</double>`, strconv.FormatFloat(x, 'f', -1, 64))
+	case []string:
+		b.WriteString(`<array><data>`)
+		for _, e := range x {
+			if err := encodeValue(b, e); err != nil {
+				return err
+			}
+		}
+		b.WriteString(`</data></array>`)
+	case []any:
+		b.WriteString(`<array><data>`)
+		for _, e := range x {
+			if err := encodeValue(b, e); err != nil {
+				return err
+			}
+		}
+		b.WriteString(`</data></array>`)
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString(`<struct>`)
+		for _, k := range keys {
+			b.WriteString(`<member><name>`)
+			xml.EscapeText(b, []byte(k))
+			b.WriteString(`</name>`)
+			if err := encodeValue(b, x[k]); err != nil {
+				return err
+			}
+			b.WriteString(`</member>`)
+		}
+		b.WriteString(`</struct>`)
+	default:
+		return fmt.Errorf("xmlrpc: cannot encode %T", v)
+	}
+	b.WriteString(`</value>`)
+	return nil
+}
+
+// ----- XML-RPC decoding -----
+
+// xmlNode is a generic element tree; XML-RPC values are small enough to
+// decode this way and then convert.
+type xmlNode struct {
+	XMLName  xml.Name
+	Content  string    `xml:",chardata"`
+	Children []xmlNode `xml:",any"`
+}
+
+func (n xmlNode) child(name string) (xmlNode, bool) {
+	for _, c := range n.Children {
+		if c.XMLName.Local == name {
+			return c, true
+		}
+	}
+	return xmlNode{}, false
+}
+
+// decodeResponse returns the single value of a methodResponse, or the fault
+// as an error.
+func decodeResponse(r io.Reader) (any, error) {
+	var root xmlNode
+	if err := xml.NewDecoder(r).Decode(&root); err != nil {
+		return nil, fmt.Errorf("xmlrpc: %w", err)
+	}
+	if root.XMLName.Local != "methodResponse" {
+		return nil, fmt.Errorf("xmlrpc: unexpected <%s>", root.XMLName.Local)
+	}
+	if f, ok := root.child("fault"); ok {
+		v, err := decodeValueNode(f)
+		if err != nil {
+			return nil, err
+		}
+		m, _ := v.(map[string]any)
+		return nil, fmt.Errorf("xmlrpc fault %v: %v", m["faultCode"], m["faultString"])
+	}
+	params, ok := root.child("params")
+	if !ok {
+		return nil, fmt.Errorf("xmlrpc: response has no params")
+	}
+	param, ok := params.child("param")
+	if !ok {
+		return nil, fmt.Errorf("xmlrpc: response has no param")
+	}
+	return decodeValueNode(param)
+}
+
+// decodeValueNode decodes the <value> child of n.
+func decodeValueNode(n xmlNode) (any, error) {
+	v, ok := n.child("value")
+	if !ok {
+		return nil, fmt.Errorf("xmlrpc: <%s> has no value", n.XMLName.Local)
+	}
+	return decodeValue(v)
+}
+
+func decodeValue(v xmlNode) (any, error) {
+	if len(v.Children) == 0 {
+		return v.Content, nil // untyped value is a string
+	}
+	t := v.Children[0]
+	text := strings.TrimSpace(t.Content)
+	switch t.XMLName.Local {
+	case "string":
+		return t.Content, nil
+	case "int", "i4", "i8":
+		i, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("xmlrpc: bad int %q", text)
+		}
+		return i, nil
+	case "boolean":
+		return text == "1", nil
+	case "double":
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("xmlrpc: bad double %q", text)
+		}
+		return f, nil
+	case "dateTime.iso8601", "base64":
+		return text, nil
+	case "nil":
+		return nil, nil
+	case "array":
+		data, _ := t.child("data")
+		out := []any{}
+		for _, c := range data.Children {
+			if c.XMLName.Local != "value" {
+				continue
+			}
+			e, err := decodeValue(c)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, e)
+		}
+		return out, nil
+	case "struct":
+		out := map[string]any{}
+		for _, m := range t.Children {
+			if m.XMLName.Local != "member" {
+				continue
+			}
+			name, _ := m.child("name")
+			e, err := decodeValueNode(m)
+			if err != nil {
+				return nil, err
+			}
+			out[strings.TrimSpace(name.Content)] = e
+		}
+		return out, nil
+	}
+	return nil, fmt.Errorf("xmlrpc: unknown type <%s>", t.XMLName.Local)
+}
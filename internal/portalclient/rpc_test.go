@@ -0,0 +1,87 @@
+package portalclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func testRPC(t *testing.T, reply string, got *string) *Client {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		*got = string(b)
+		io.WriteString(w, reply)
+	}))
+	t.Cleanup(srv.Close)
+	rpc := &rpcClient{url: srv.URL, http: srv.Client()}
+	return &Client{rpc: rpc}
+}
+
+const okReply = `<?xml version="1.0"?>
+<methodResponse><params><param><value><struct>
+  <member><name>code</name><value><int>0</int></value></member>
+  <member><name>value</name><value><array><data><value>a</value><value><i4>2</i4></value></data></array></value></member>
+  <member><name>output</name><value><string>{"x": 1} &amp; more</string></value></member>
+</struct></value></param></params></methodResponse>`
+
+func TestCallEncodesRequestAndDecodesResponse(t *testing.T) {
+	var body string
+	c := testRPC(t, okReply, &body)
+	resp, err := call(c, "reboot", map[string]any{"experiment": "p,e", "nodes": "n0,n1", "start": int64(1700000000), "force": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"<methodName>portal.reboot</methodName>",
+		"<double>0.1</double>",
+		"<member><name>experiment</name><value><string>p,e</string></value></member>",
+		"<member><name>force</name><value><boolean>1</boolean></value></member>",
+		"<member><name>start</name><value><int>1700000000</int></value></member>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("request missing %q:\n%s", want, body)
+		}
+	}
+	if resp.Code != 0 || resp.Output != `{"x": 1} & more` {
+		t.Fatalf("resp = %+v", resp)
+	}
+	v, ok := resp.Value.([]any)
+	if !ok || len(v) != 2 || v[0] != "a" || v[1] != 2 {
+		t.Fatalf("value = %#v", resp.Value)
+	}
+}
+
+func TestCallNonZeroCode(t *testing.T) {
+	var body string
+	c := testRPC(t, `<methodResponse><params><param><value><struct>
+  <member><name>code</name><value><int>12</int></value></member>
+  <member><name>output</name><value><string>No such experiment</string></value></member>
+</struct></value></param></params></methodResponse>`, &body)
+	resp, err := call(c, "experimentStatus", map[string]any{})
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if resp == nil || resp.Code != ResponseSearchFailed || !IsNotFound(resp, err) || !IsNotFound(nil, err) {
+		t.Fatalf("resp = %+v, err = %v", resp, err)
+	}
+}
+
+func TestCallFault(t *testing.T) {
+	var body string
+	c := testRPC(t, `<methodResponse><fault><value><struct>
+  <member><name>faultCode</name><value><int>1</int></value></member>
+  <member><name>faultString</name><value><string>no such method</string></value></member>
+</struct></value></fault></methodResponse>`, &body)
+	if _, err := call(c, "bogus", map[string]any{}); err == nil || !strings.Contains(err.Error(), "no such method") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestEncodeValueRejectsUnknownType(t *testing.T) {
+	if _, err := encodeCall("portal.x", struct{}{}); err == nil {
+		t.Fatal("expected error")
+	}
+}
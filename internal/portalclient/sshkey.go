@@ -0,0 +1,54 @@
+package portalclient
+
+import (
+	"encoding/json"
+	"fmt"
+
+	portal "github.com/csc478-wcu/portalctl/portal"
+)
+
+// ParseSSHKeys decodes a JSON array of public key lines, or of objects with
+// a "key" field.
+func ParseSSHKeys(s string) ([]string, error) {
+	var out []string
+	err := decodeLoose(s, func(b []byte) error {
+		out = nil
+		if err := json.Unmarshal(b, &out); err == nil {
+			return nil
+		}
+		var objs []struct {
+			Key string `json:"key"`
+		}
+		if err := json.Unmarshal(b, &objs); err != nil {
+			return err
+		}
+		out = nil // the failed []string decode may have left entries
+		for _, o := range objs {
+			out = append(out, o.Key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no decodable key list in portal output (len=%d)", len(s))
+	}
+	return out, nil
+}
+
+// ListSSHKeys invokes portal.listSSHKeys for the authenticated user.
+func ListSSHKeys(c *Client) ([]string, error) {
+	resp, err := call(c, "listSSHKeys", map[string]any{})
+	if err != nil {
+		return nil, err
+	}
+	return ParseSSHKeys(resp.Output)
+}
+
+// AddSSHKey invokes portal.addSSHKey for the authenticated user.
+func AddSSHKey(c *Client, key string) (*portal.EmulabResponse, error) {
+	return call(c, "addSSHKey", map[string]any{"key": key})
+}
+
+// DeleteSSHKey invokes portal.deleteSSHKey for the authenticated user.
+func DeleteSSHKey(c *Client, key string) (*portal.EmulabResponse, error) {
+	return call(c, "deleteSSHKey", map[string]any{"key": key})
+}
@@ -0,0 +1,32 @@
+package portalclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSSHKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{name: "lines", in: `["ssh-ed25519 AAAA a", "ssh-rsa BBBB b"]`, want: []string{"ssh-ed25519 AAAA a", "ssh-rsa BBBB b"}},
+		{name: "objects", in: `[{"key": "ssh-ed25519 AAAA a", "fingerprint": "x"}]`, want: []string{"ssh-ed25519 AAAA a"}},
+		{name: "banner then array", in: "Your keys:\n[\"ssh-ed25519 AAAA a\"]\n", want: []string{"ssh-ed25519 AAAA a"}},
+		{name: "empty", in: "[]", want: []string{}},
+		{name: "no json", in: "not logged in", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseSSHKeys(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package portalclient
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NodeStartup is the startup (execute service) state of one node as reported
+// in the experimentStatus JSON. ExitCode is nil until the services exit.
+type NodeStartup struct {
+	State    string
+	ExitCode *int
+}
+
+type startupStatusJSON struct {
+	AggregateStatus map[string]struct {
+		Nodes map[string]struct {
+			ClientID      string          `json:"client_id"`
+			ExecuteState  string          `json:"execute_state"`
+			ExecuteStatus json.RawMessage `json:"execute_status"`
+		} `json:"nodes"`
+	} `json:"aggregate_status"`
+}
+
+// ParseStartupStatus extracts per-node startup state from status output,
+// keyed by node (client) name. Nodes without execute services are omitted.
+func ParseStartupStatus(s string) (map[string]NodeStartup, error) {
+	var raw startupStatusJSON
+	err := decodeLoose(s, func(b []byte) error {
+		raw = startupStatusJSON{}
+		return json.Unmarshal(b, &raw)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("no decodable JSON object found in status output (len=%d)", len(s))
+	}
+
+	out := map[string]NodeStartup{}
+	for _, agg := range raw.AggregateStatus {
+		for id, n := range agg.Nodes {
+			state := strings.ToLower(strings.TrimSpace(n.ExecuteState))
+			if state == "" || state == "none" {
+				continue
+			}
+			name := n.ClientID
+			if name == "" {
+				name = id
+			}
+			out[name] = NodeStartup{State: state, ExitCode: parseExitCode(n.ExecuteStatus)}
+		}
+	}
+	return out, nil
+}
+
+// The portal reports the exit code as a number or a numeric string.
+func parseExitCode(b json.RawMessage) *int {
+	v := strings.Trim(strings.TrimSpace(string(b)), `"`)
+	if v == "" || v == "null" {
+		return nil
+	}
+	code, err := strconv.Atoi(v)
+	if err != nil {
+		return nil
+	}
+	return &code
+}
@@ -2,50 +2,54 @@ package provider
 
 import (
 	"context"
-	"time"
 
 	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
 	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
 
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/availability"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/diskimage"
 	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/experiment"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/experimentlist"
 	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/reservation"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/sshkey"
 )
 
 func Provider() *schema.Provider {
 	p := &schema.Provider{
 		Schema: map[string]*schema.Schema{
 			"project":  {Type: schema.TypeString, Optional: true},
-			"pem_path": {Type: schema.TypeString, Optional: true, Default: "~/cloudlab.pem"},
-			"server":   {Type: schema.TypeString, Optional: true, Default: "boss.emulab.net"},
-			"port":     {Type: schema.TypeInt, Optional: true, Default: 3069},
-			"path":     {Type: schema.TypeString, Optional: true, Default: "/usr/testbed"},
-			"timeout":  {Type: schema.TypeString, Optional: true, Default: "10m"},
+			"pem_path": {Type: schema.TypeString, Optional: true, Default: portalclient.DefaultPemPath},
+			"server":   {Type: schema.TypeString, Optional: true, Default: portalclient.DefaultServer},
+			"port":     {Type: schema.TypeInt, Optional: true, Default: portalclient.DefaultPort},
+			"path":     {Type: schema.TypeString, Optional: true, Default: portalclient.DefaultPath},
+			"timeout":  {Type: schema.TypeString, Optional: true, Default: portalclient.DefaultTimeout},
 		},
 		ResourcesMap: map[string]*schema.Resource{
 			"cloudlab_portal_experiment": experiment.Resource(),
+			"cloudlab_reservation":       reservation.Resource(),
+			"cloudlab_disk_image":        diskimage.Resource(),
+			"cloudlab_ssh_key":           sshkey.Resource(),
+		},
+		DataSourcesMap: map[string]*schema.Resource{
+			"cloudlab_resource_availability": availability.DataSource(),
+			"cloudlab_experiments":           experimentlist.DataSource(),
+			"cloudlab_image":                 diskimage.DataSource(),
 		},
 	}
 
 	p.ConfigureContextFunc = func(ctx context.Context, d *schema.ResourceData) (interface{}, diag.Diagnostics) {
-		to, _ := time.ParseDuration(d.Get("timeout").(string))
-		opts := portalclient.Options{
+		cfg, err := portalclient.Configure(portalclient.Settings{
+			Project: d.Get("project").(string),
+			PemPath: d.Get("pem_path").(string),
 			Server:  d.Get("server").(string),
 			Port:    d.Get("port").(int),
 			Path:    d.Get("path").(string),
-			CertPEM: d.Get("pem_path").(string),
-			KeyPEM:  d.Get("pem_path").(string),
-			Verify:  false, // always self-signed
-			Timeout: to,
-		}
-		cli, err := portalclient.New(opts)
+			Timeout: d.Get("timeout").(string),
+		})
 		if err != nil {
 			return nil, diag.FromErr(err)
 		}
-		cfg := &portalclient.Config{
-			Client:  cli,
-			Project: d.Get("project").(string),
-			PemPath: d.Get("pem_path").(string),
-		}
 		return cfg, nil
 	}
 
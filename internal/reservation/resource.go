@@ -0,0 +1,136 @@
+package reservation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/validation"
+)
+
+func expand(d *schema.ResourceData, cfg *portalclient.Config) (portalclient.Reservation, error) {
+	r := portalclient.Reservation{
+		UUID:         d.Id(),
+		Project:      d.Get("project").(string),
+		Aggregate:    d.Get("aggregate").(string),
+		HardwareType: d.Get("hardware_type").(string),
+		Count:        d.Get("node_count").(int),
+		Start:        d.Get("start").(string),
+		End:          d.Get("end").(string),
+		Reason:       d.Get("reason").(string),
+	}
+	if r.Project == "" {
+		r.Project = cfg.Project
+	}
+	if r.Aggregate == "" || !validation.IsValidAggregate(r.Aggregate) {
+		return r, fmt.Errorf("aggregate %q is not a recognized URN", r.Aggregate)
+	}
+	start, _ := time.Parse(time.RFC3339, r.Start)
+	end, _ := time.Parse(time.RFC3339, r.End)
+	if !end.After(start) {
+		return r, fmt.Errorf("end (%s) must be after start (%s)", r.End, r.Start)
+	}
+	return r, nil
+}
+
+func resourceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	r, err := expand(d, cfg)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	tflog.Info(ctx, "creating reservation", map[string]any{
+		"project": r.Project, "aggregate": r.Aggregate, "type": r.HardwareType, "count": r.Count,
+	})
+	out, err := portalclient.CreateReservation(cfg.Client, r)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(out.UUID)
+	return resourceRead(ctx, d, meta)
+}
+
+func resourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	if d.Id() == "" {
+		return nil
+	}
+	project := d.Get("project").(string)
+	if project == "" {
+		project = cfg.Project
+	}
+
+	r, err := portalclient.GetReservation(cfg.Client, project, d.Get("aggregate").(string), d.Id())
+	if portalclient.IsNotFound(nil, err) {
+		tflog.Warn(ctx, "reservation not found during read; clearing state",
+			map[string]any{"project": project, "uuid": d.Id(), "error": err})
+		d.SetId("")
+		return nil
+	}
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	_ = d.Set("uuid", r.UUID)
+	_ = d.Set("status", r.Status)
+	if r.Count > 0 {
+		_ = d.Set("node_count", r.Count)
+	}
+	if r.Reason != "" {
+		_ = d.Set("reason", r.Reason)
+	}
+	// Keep the configured spelling when the portal returns the same instant.
+	setTime(d, "start", r.Start)
+	setTime(d, "end", r.End)
+	return nil
+}
+
+func setTime(d *schema.ResourceData, key, v string) {
+	got, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		return
+	}
+	if cur, err := time.Parse(time.RFC3339, d.Get(key).(string)); err == nil && cur.Equal(got) {
+		return
+	}
+	_ = d.Set(key, v)
+}
+
+func resourceUpdate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	r, err := expand(d, cfg)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	tflog.Info(ctx, "updating reservation", map[string]any{"project": r.Project, "uuid": r.UUID})
+	if _, err := portalclient.UpdateReservation(cfg.Client, r); err != nil {
+		return diag.FromErr(err)
+	}
+	return resourceRead(ctx, d, meta)
+}
+
+func resourceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	if d.Id() == "" {
+		return nil
+	}
+	project := d.Get("project").(string)
+	if project == "" {
+		project = cfg.Project
+	}
+
+	tflog.Info(ctx, "deleting reservation", map[string]any{"project": project, "uuid": d.Id()})
+	if _, err := portalclient.DeleteReservation(cfg.Client, project, d.Get("aggregate").(string), d.Id()); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("")
+	return nil
+}
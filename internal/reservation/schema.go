@@ -0,0 +1,30 @@
+package reservation
+
+import (
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/validation"
+)
+
+// Resource is cloudlab_reservation. Terraform reserves "count", so the
+// number of nodes is node_count.
+func Resource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCreate,
+		ReadContext:   resourceRead,
+		UpdateContext: resourceUpdate,
+		DeleteContext: resourceDelete,
+		Schema: map[string]*schema.Schema{
+			"project":       {Type: schema.TypeString, Optional: true, ForceNew: true},
+			"aggregate":     {Type: schema.TypeString, Required: true, ForceNew: true},
+			"hardware_type": {Type: schema.TypeString, Required: true, ForceNew: true},
+			"node_count":    {Type: schema.TypeInt, Required: true, ValidateFunc: validation.IntAtLeast(1)},
+			"start":         {Type: schema.TypeString, Required: true, ValidateFunc: validation.IsRFC3339Time},
+			"end":           {Type: schema.TypeString, Required: true, ValidateFunc: validation.IsRFC3339Time},
+			"reason":        {Type: schema.TypeString, Required: true},
+
+			// outputs
+			"uuid":   {Type: schema.TypeString, Computed: true},
+			"status": {Type: schema.TypeString, Computed: true},
+		},
+	}
+}
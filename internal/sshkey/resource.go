@@ -0,0 +1,101 @@
+package sshkey
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/helper/schema"
+
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/portalclient"
+	"github.com/csc478-wcu/terraform-provider-cloudlab/internal/validation"
+)
+
+// Resource is cloudlab_ssh_key: one public key on the authenticated user's
+// portal account. The ID is the key's SHA256 fingerprint.
+func Resource() *schema.Resource {
+	return &schema.Resource{
+		CreateContext: resourceCreate,
+		ReadContext:   resourceRead,
+		DeleteContext: resourceDelete,
+		Schema: map[string]*schema.Schema{
+			"public_key": {
+				Type:     schema.TypeString,
+				Required: true,
+				ForceNew: true,
+				ValidateFunc: func(v interface{}, k string) ([]string, []error) {
+					if _, _, err := validation.ParseSSHPublicKey(v.(string)); err != nil {
+						return nil, []error{fmt.Errorf("%s: %w", k, err)}
+					}
+					return nil, nil
+				},
+				// Trailing newlines and comment changes are not a new key.
+				DiffSuppressFunc: func(k, old, new string, d *schema.ResourceData) bool {
+					return sameKey(old, new)
+				},
+			},
+
+			// outputs
+			"fingerprint": {Type: schema.TypeString, Computed: true},
+		},
+	}
+}
+
+func sameKey(a, b string) bool {
+	ta, ba, errA := validation.ParseSSHPublicKey(a)
+	tb, bb, errB := validation.ParseSSHPublicKey(b)
+	return errA == nil && errB == nil && ta == tb && ba == bb
+}
+
+func resourceCreate(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	key := strings.TrimSpace(d.Get("public_key").(string))
+	fp, err := validation.SSHFingerprint(key)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	tflog.Info(ctx, "adding ssh key", map[string]any{"fingerprint": fp})
+	if _, err := portalclient.AddSSHKey(cfg.Client, key); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId(fp)
+	return resourceRead(ctx, d, meta)
+}
+
+func resourceRead(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	if d.Id() == "" {
+		return nil
+	}
+	keys, err := portalclient.ListSSHKeys(cfg.Client)
+	if err != nil {
+		return diag.FromErr(err)
+	}
+	for _, k := range keys {
+		if fp, err := validation.SSHFingerprint(k); err == nil && fp == d.Id() {
+			_ = d.Set("fingerprint", fp)
+			return nil
+		}
+	}
+	tflog.Warn(ctx, "ssh key not found during read; clearing state", map[string]any{"fingerprint": d.Id()})
+	d.SetId("")
+	return nil
+}
+
+func resourceDelete(ctx context.Context, d *schema.ResourceData, meta interface{}) diag.Diagnostics {
+	cfg := meta.(*portalclient.Config)
+
+	if d.Id() == "" {
+		return nil
+	}
+	tflog.Info(ctx, "deleting ssh key", map[string]any{"fingerprint": d.Id()})
+	if _, err := portalclient.DeleteSSHKey(cfg.Client, strings.TrimSpace(d.Get("public_key").(string))); err != nil {
+		return diag.FromErr(err)
+	}
+	d.SetId("")
+	return nil
+}
@@ -0,0 +1,28 @@
+package sshkey
+
+import "testing"
+
+const testKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIE1uInMgGp7/qhI3vLkefIYrtgLgP3va8D/bRZEB2Jxz"
+
+func TestSameKey(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want bool
+	}{
+		{name: "identical", a: testKey, b: testKey, want: true},
+		{name: "comment only", a: testKey + " alice@laptop", b: testKey + " alice@desktop", want: true},
+		{name: "comment added", a: testKey, b: testKey + " alice@laptop", want: true},
+		{name: "trailing newline", a: testKey + "\n", b: testKey, want: true},
+		{name: "different key", a: testKey, b: "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQDBTd348S7rIZ3a1ILL3eMuFNXqZeuMBmru0DxCGRRJqi2VWDXOwxzuQaRqaBlPH4G+1HpU0iB/NGLNGIKLZM9e7CHcAOVuv92qLegrFD4KEavB4zFKKo+9RCZxAjPJWvNQ1oXBnWH0yhXvFFUY7r8QMGHn+CBrg46UiWzbMqioEw=="},
+		{name: "invalid", a: "not a key", b: "not a key"},
+		{name: "empty old", a: "", b: testKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sameKey(tt.a, tt.b); got != tt.want {
+				t.Fatalf("sameKey = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
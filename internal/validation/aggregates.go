@@ -15,3 +15,12 @@ func IsValidAggregate(urn string) bool {
 	_, ok := Aggregates[urn]
 	return ok
 }
+
+// StitchingDomains are the clusters that can terminate a stitched or
+// GRE-tunnelled link.
+var StitchingDomains = map[string]bool{
+	"emulab.net":          true,
+	"utah.cloudlab.us":    true,
+	"clemson.cloudlab.us": true,
+	"wisc.cloudlab.us":    true,
+}
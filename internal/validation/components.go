@@ -0,0 +1,69 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// componentPrefixes maps a cluster domain to node-name prefixes whose
+// hardware type is known. Names not listed here are not type-checked.
+var componentPrefixes = map[string][]struct{ Prefix, HardwareType string }{
+	"utah.cloudlab.us": {
+		{"ms", "m510"},
+		{"hp", "xl170"},
+	},
+	"wisc.cloudlab.us": {
+		{"c220g1-", "c220g1"},
+		{"c220g2-", "c220g2"},
+		{"c220g5-", "c220g5"},
+		{"c240g1-", "c240g1"},
+		{"c240g2-", "c240g2"},
+		{"c240g5-", "c240g5"},
+	},
+	"clemson.cloudlab.us": {
+		{"clnode", "c6320"},
+	},
+}
+
+// ParseComponentURN splits "urn:publicid:IDN+<domain>+node+<name>".
+func ParseComponentURN(urn string) (domain, name string, err error) {
+	parts := strings.Split(strings.TrimPrefix(urn, "urn:publicid:IDN+"), "+")
+	if !strings.HasPrefix(urn, "urn:publicid:IDN+") || len(parts) != 3 || parts[1] != "node" || parts[0] == "" || parts[2] == "" {
+		return "", "", fmt.Errorf("component_id %q is not a node URN (urn:publicid:IDN+<site>+node+<name>)", urn)
+	}
+	return parts[0], parts[2], nil
+}
+
+// CheckComponent verifies a component URN is on a known cluster, on the given
+// aggregate when one is set, and not of a hardware type other than hwType.
+func CheckComponent(urn, aggregate, hwType string) error {
+	domain, name, err := ParseComponentURN(urn)
+	if err != nil {
+		return err
+	}
+	if aggregate != "" {
+		if d := Aggregates[aggregate]; d != domain {
+			return fmt.Errorf("component_id %q is not on aggregate %q", urn, aggregate)
+		}
+	} else if !knownDomain(domain) {
+		return fmt.Errorf("component_id %q is not on a recognized aggregate", urn)
+	}
+	if hwType == "" {
+		return nil
+	}
+	for _, p := range componentPrefixes[domain] {
+		if strings.HasPrefix(name, p.Prefix) && p.HardwareType != hwType {
+			return fmt.Errorf("component_id %q is a %s, not hardware_type %q", urn, p.HardwareType, hwType)
+		}
+	}
+	return nil
+}
+
+func knownDomain(domain string) bool {
+	for _, d := range Aggregates {
+		if d == domain {
+			return true
+		}
+	}
+	return false
+}
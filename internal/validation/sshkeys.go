@@ -0,0 +1,47 @@
+package validation
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+var sshKeyTypes = map[string]bool{
+	"ssh-rsa":                            true,
+	"ssh-ed25519":                        true,
+	"ecdsa-sha2-nistp256":                true,
+	"ecdsa-sha2-nistp384":                true,
+	"ecdsa-sha2-nistp521":                true,
+	"sk-ssh-ed25519@openssh.com":         true,
+	"sk-ecdsa-sha2-nistp256@openssh.com": true,
+}
+
+// ParseSSHPublicKey splits an authorized_keys line into its type and base64
+// blob, checking the blob encodes the same type.
+func ParseSSHPublicKey(line string) (typ, blob string, err error) {
+	f := strings.Fields(line)
+	if len(f) < 2 || !sshKeyTypes[f[0]] {
+		return "", "", fmt.Errorf("not an OpenSSH public key (want \"<type> <base64> [comment]\")")
+	}
+	raw, err := base64.StdEncoding.DecodeString(f[1])
+	if err != nil || len(raw) < 4 {
+		return "", "", fmt.Errorf("public key data is not valid base64")
+	}
+	n := int(raw[0])<<24 | int(raw[1])<<16 | int(raw[2])<<8 | int(raw[3])
+	if n != len(f[0]) || len(raw) < 4+n || string(raw[4:4+n]) != f[0] {
+		return "", "", fmt.Errorf("public key data does not match key type %s", f[0])
+	}
+	return f[0], f[1], nil
+}
+
+// SSHFingerprint returns the OpenSSH SHA256 fingerprint of a public key.
+func SSHFingerprint(line string) (string, error) {
+	_, blob, err := ParseSSHPublicKey(line)
+	if err != nil {
+		return "", err
+	}
+	raw, _ := base64.StdEncoding.DecodeString(blob)
+	sum := sha256.Sum256(raw)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]), nil
+}